@@ -0,0 +1,59 @@
+package cache_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/cache"
+	"testing"
+	"time"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := cache.NewLRU(2, 0)
+	c.Set("bulbasaur", 1)
+	c.Set("ivysaur", 2)
+
+	_, ok := c.Get("bulbasaur")
+	assert.True(t, ok)
+
+	c.Set("venusaur", 3)
+	_, ok = c.Get("ivysaur")
+	assert.False(t, ok)
+	_, ok = c.Get("bulbasaur")
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	assert.True(t, c.Delete("bulbasaur"))
+	assert.False(t, c.Delete("bulbasaur"))
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRUExpiry(t *testing.T) {
+	c := cache.NewLRU(10, 20*time.Millisecond)
+	c.Set("pikachu", 1)
+
+	value, ok := c.Get("pikachu")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = c.Get("pikachu")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRUSetUntil(t *testing.T) {
+	c := cache.NewLRU(10, time.Hour)
+	c.SetUntil("pikachu", 1, time.Now().Add(20*time.Millisecond))
+	c.SetUntil("eevee", 2, time.Time{})
+
+	_, ok := c.Get("pikachu")
+	assert.True(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = c.Get("pikachu")
+	assert.False(t, ok)
+	_, ok = c.Get("eevee")
+	assert.True(t, ok)
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"pokeapi/middleware"
+	"strings"
+)
+
+// NewAuthConfig reads AUTH_API_KEYS as a comma separated list of
+// subject:key:role|role entries plus the optional JWT verification keys.
+func NewAuthConfig() (middleware.AuthConfig, error) {
+	authConfig := middleware.AuthConfig{
+		Disabled: os.Getenv("AUTH_DISABLED") == "true",
+		JWT: middleware.JWTVerifier{
+			Issuer:   os.Getenv("AUTH_JWT_ISSUER"),
+			Audience: os.Getenv("AUTH_JWT_AUDIENCE"),
+		},
+	}
+
+	for _, entry := range strings.Split(os.Getenv("AUTH_API_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return middleware.AuthConfig{}, fmt.Errorf("invalid AUTH_API_KEYS entry, expected subject:key:roles")
+		}
+		authConfig.APIKeys = append(authConfig.APIKeys, middleware.APIKey{
+			Key: parts[1],
+			Principal: middleware.Principal{
+				Subject: parts[0],
+				Roles:   strings.Split(parts[2], "|"),
+			},
+		})
+	}
+
+	if secret := os.Getenv("AUTH_JWT_HS256_SECRET"); secret != "" {
+		authConfig.JWT.HMACSecret = []byte(secret)
+	}
+
+	publicKey := os.Getenv("AUTH_JWT_RS256_PUBLIC_KEY")
+	if path := os.Getenv("AUTH_JWT_RS256_PUBLIC_KEY_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return middleware.AuthConfig{}, err
+		}
+		publicKey = string(content)
+	}
+	if publicKey != "" {
+		key, err := parseRSAPublicKey(strings.ReplaceAll(publicKey, `\n`, "\n"))
+		if err != nil {
+			return middleware.AuthConfig{}, err
+		}
+		authConfig.JWT.RSAPublicKey = key
+	}
+
+	return authConfig, nil
+}
+
+func parseRSAPublicKey(content string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(content))
+	if block == nil {
+		return nil, fmt.Errorf("invalid RS256 public key: no PEM block")
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid RS256 public key: not an RSA key")
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"os"
+	"pokeapi/cache"
+	"pokeapi/repository"
+	"strconv"
+	"time"
+)
+
+func NewCachedPokeDataSource(source repository.PokeDataSource, db *gorm.DB) (*repository.CachedPokeDataSource, error) {
+	if os.Getenv("CACHE_ENABLED") == "false" {
+		return nil, nil
+	}
+
+	size, err := intEnv("CACHE_SIZE", 1000)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := durationEnv("CACHE_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	var store repository.PokeCacheStore
+	if os.Getenv("CACHE_PERSISTENT") == "true" {
+		cacheRepository := repository.NewCacheRepository(db)
+		store = cacheRepository
+	}
+
+	return repository.NewCachedPokeDataSource(source, cache.NewLRU(size, ttl), store), nil
+}
+
+func intEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return number, nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"pokeapi/repository"
+	"time"
+)
+
+func NewPokeDataSource() (repository.PokeDataSource, error) {
+	switch source := os.Getenv("POKEAPI_SOURCE"); source {
+	case "", "http":
+		timeout, err := durationEnv("POKEAPI_TIMEOUT", repository.DefaultPokeApiTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewPokeApiDataSource(os.Getenv("POKEAPI_BASE_URL"), timeout, nil), nil
+	case "file":
+		dir := os.Getenv("POKEAPI_FIXTURE_DIR")
+		if dir == "" {
+			return nil, fmt.Errorf("POKEAPI_FIXTURE_DIR is required when POKEAPI_SOURCE=file")
+		}
+		return repository.NewFilePokeDataSource(dir), nil
+	default:
+		return nil, fmt.Errorf("unknown POKEAPI_SOURCE %q", source)
+	}
+}
+
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return duration, nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+)
+
+func NewFightConfig() (model.FightConfig, error) {
+	minParticipants, err := intEnv("FIGHT_MIN_PARTICIPANTS", 2)
+	if err != nil {
+		return model.FightConfig{}, err
+	}
+	maxParticipants, err := intEnv("FIGHT_MAX_PARTICIPANTS", 10)
+	if err != nil {
+		return model.FightConfig{}, err
+	}
+	if minParticipants < 1 || maxParticipants < minParticipants {
+		return model.FightConfig{}, fmt.Errorf("invalid fight participant limits %d..%d", minParticipants, maxParticipants)
+	}
+
+	fightConfig := model.FightConfig{
+		ScoringRule:     os.Getenv("SCORING_RULE"),
+		CPFormula:       os.Getenv("CP_FORMULA"),
+		MinParticipants: minParticipants,
+		MaxParticipants: maxParticipants,
+	}
+
+	if table := os.Getenv("SCORING_CUSTOM_TABLE"); table != "" {
+		scoringTable, err := pokemon.ParseScoringTable(table)
+		if err != nil {
+			return model.FightConfig{}, err
+		}
+		fightConfig.ScoringTable = scoringTable
+	}
+
+	if _, err := pokemon.NewScoringRule(fightConfig.ScoringRule, fightConfig.ScoringTable); err != nil {
+		return model.FightConfig{}, err
+	}
+
+	fightConfig.TieBreakers, err = pokemon.ParseTieBreakers(os.Getenv("FIGHT_TIE_BREAKERS"))
+	if err != nil {
+		return model.FightConfig{}, err
+	}
+
+	if weights := os.Getenv("CP_WEIGHTS"); weights != "" {
+		cpWeights, err := pokemon.ParseCPWeights(weights)
+		if err != nil {
+			return model.FightConfig{}, err
+		}
+		fightConfig.CPWeights = cpWeights
+	}
+
+	if _, err := pokemon.NewCPFormula(fightConfig.CPFormula, fightConfig.CPWeights); err != nil {
+		return model.FightConfig{}, err
+	}
+
+	return fightConfig, nil
+}
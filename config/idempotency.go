@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"os"
+	"pokeapi/middleware"
+	"pokeapi/repository"
+	"time"
+)
+
+// NewIdempotency also returns a function that stops the background cleanup
+// of expired keys, to call when the app shuts down.
+func NewIdempotency(db *gorm.DB) (middleware.Idempotency, func(), error) {
+	ttl, err := durationEnv("IDEMPOTENCY_TTL", 24*time.Hour)
+	if err != nil {
+		return middleware.Idempotency{}, nil, err
+	}
+	lease, err := durationEnv("IDEMPOTENCY_LEASE", time.Minute)
+	if err != nil {
+		return middleware.Idempotency{}, nil, err
+	}
+
+	var store middleware.IdempotencyStore
+	stop := func() {}
+	switch backend := os.Getenv("IDEMPOTENCY_STORE"); backend {
+	case "", "db":
+		idempotencyRepository := repository.NewIdempotencyRepository(db)
+		store = idempotencyRepository
+		ticker := time.NewTicker(time.Hour)
+		done := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case now := <-ticker.C:
+					_, _ = idempotencyRepository.DeleteExpired(now)
+				case <-done:
+					return
+				}
+			}
+		}()
+		stop = func() {
+			ticker.Stop()
+			close(done)
+		}
+	case "memory":
+		store = middleware.NewMemoryIdempotencyStore()
+	default:
+		return middleware.Idempotency{}, nil, fmt.Errorf("unknown IDEMPOTENCY_STORE %q", backend)
+	}
+
+	return middleware.NewIdempotency(store, ttl, lease), stop, nil
+}
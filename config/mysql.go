@@ -20,14 +20,30 @@ func Connect() (*gorm.DB, error) {
 	Database, err := gorm.Open(mysql.Open(databaseUri), &gorm.Config{
 		SkipDefaultTransaction: true,
 		PrepareStmt:            true,
+		TranslateError:         true,
 	})
 
 	if err != nil {
 		panic(err)
 	}
 
+	Database.AutoMigrate(&entity.Trainer{})
+	Database.AutoMigrate(&entity.Season{})
+	Database.AutoMigrate(&entity.SeasonStanding{})
 	Database.AutoMigrate(&entity.FightHistory{})
 	Database.AutoMigrate(&entity.FightHistoryDetail{})
+	Database.AutoMigrate(&entity.BattleTurn{})
+	Database.AutoMigrate(&entity.FightTeam{})
+	Database.AutoMigrate(&entity.FightTeamMember{})
+	Database.AutoMigrate(&entity.CancellationAudit{})
+	Database.AutoMigrate(&entity.CancellationAuditChange{})
+	Database.AutoMigrate(&entity.Tournament{})
+	Database.AutoMigrate(&entity.TournamentEntrant{})
+	Database.AutoMigrate(&entity.TournamentMatch{})
+	Database.AutoMigrate(&entity.PokemonRating{})
+	Database.AutoMigrate(&entity.RatingHistory{})
+	Database.AutoMigrate(&entity.CacheEntry{})
+	Database.AutoMigrate(&entity.IdempotencyKey{})
 
 	return Database, nil
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"pokeapi/middleware"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func NewRateLimiter() (middleware.RateLimiter, middleware.RateLimit, middleware.RateLimit, middleware.RateLimit, error) {
+	read, err := rateLimitEnv("read", "RATE_LIMIT_READ", "120/m")
+	if err != nil {
+		return middleware.RateLimiter{}, middleware.RateLimit{}, middleware.RateLimit{}, middleware.RateLimit{}, err
+	}
+	write, err := rateLimitEnv("write", "RATE_LIMIT_WRITE", "10/m")
+	if err != nil {
+		return middleware.RateLimiter{}, middleware.RateLimit{}, middleware.RateLimit{}, middleware.RateLimit{}, err
+	}
+	credentials, err := rateLimitEnv("credentials", "RATE_LIMIT_CREDENTIALS", "60/m")
+	if err != nil {
+		return middleware.RateLimiter{}, middleware.RateLimit{}, middleware.RateLimit{}, middleware.RateLimit{}, err
+	}
+
+	var store middleware.RateLimitStore
+	switch backend := os.Getenv("RATE_LIMIT_STORE"); backend {
+	case "", "memory":
+		store = middleware.NewMemoryRateLimitStore()
+	default:
+		return middleware.RateLimiter{}, middleware.RateLimit{}, middleware.RateLimit{}, middleware.RateLimit{}, fmt.Errorf("unknown RATE_LIMIT_STORE %q", backend)
+	}
+
+	rateLimiter := middleware.NewRateLimiter(store, os.Getenv("RATE_LIMIT_DISABLED") == "true")
+	return rateLimiter, read, write, credentials, nil
+}
+
+// rateLimitEnv parses limits such as "10/m" or "100/30s": the bucket refills
+// that many tokens per period and holds at most <KEY>_BURST tokens, which
+// defaults to the same number.
+func rateLimitEnv(name string, key string, fallback string) (middleware.RateLimit, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		value = fallback
+	}
+
+	rate, period, ok := strings.Cut(value, "/")
+	count, err := strconv.Atoi(rate)
+	if !ok || err != nil || count < 1 {
+		return middleware.RateLimit{}, fmt.Errorf("invalid %s %q", key, value)
+	}
+
+	units := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}
+	duration, ok := units[period]
+	if !ok {
+		duration, err = time.ParseDuration(period)
+		if err != nil || duration <= 0 {
+			return middleware.RateLimit{}, fmt.Errorf("invalid %s %q", key, value)
+		}
+	}
+
+	burst, err := intEnv(key+"_BURST", count)
+	if err != nil {
+		return middleware.RateLimit{}, err
+	}
+
+	return middleware.RateLimit{
+		Name:   name,
+		Rate:   float64(count),
+		Burst:  burst,
+		Period: duration,
+	}, nil
+}
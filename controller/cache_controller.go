@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"pokeapi/middleware"
+	"pokeapi/model"
+	"pokeapi/service"
+)
+
+type CacheController struct {
+	CacheService service.CacheService
+	Auth         middleware.Auth
+}
+
+func NewCacheController(cacheService *service.CacheService, auth *middleware.Auth) CacheController {
+	return CacheController{
+		CacheService: *cacheService,
+		Auth:         *auth,
+	}
+}
+
+func (c CacheController) Route(app fiber.Router) {
+	app.Get("/cache", c.Auth.Require(middleware.RoleAdmin), c.Stats)
+	app.Delete("/cache", c.Auth.Require(middleware.RoleAdmin), c.PurgeAll)
+	app.Delete("/cache/*", c.Auth.Require(middleware.RoleAdmin), c.PurgeEntry)
+}
+
+func (c CacheController) Stats(ctx *fiber.Ctx) error {
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: c.CacheService.Stats(),
+	})
+}
+
+func (c CacheController) PurgeAll(ctx *fiber.Ctx) error {
+	if err := c.CacheService.PurgeAll(); err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: c.CacheService.Stats(),
+	})
+}
+
+func (c CacheController) PurgeEntry(ctx *fiber.Ctx) error {
+	key := ctx.Params("*")
+	deleted, err := c.CacheService.PurgeEntry(key)
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: fiber.Map{
+			"key":     key,
+			"deleted": deleted,
+		},
+	})
+}
@@ -1,33 +1,47 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"math"
 	"net/http"
 	"pokeapi/helper"
+	"pokeapi/middleware"
 	"pokeapi/model"
+	"pokeapi/repository"
 	"pokeapi/service"
 	"strconv"
+	"time"
 )
 
 type PokeController struct {
 	PokeService service.PokeService
+	Auth        middleware.Auth
 }
 
-func NewPokeController(pokeService *service.PokeService) PokeController {
+func NewPokeController(pokeService *service.PokeService, auth *middleware.Auth) PokeController {
 	return PokeController{
 		PokeService: *pokeService,
+		Auth:        *auth,
 	}
 }
 
 func (c PokeController) Route(app fiber.Router) {
 	app.Get("/pokemon", c.GetAll)
 	app.Get("/pokemon/:name", c.GetOne)
-	app.Post("/fight", c.Fight)
+	app.Get("/pokemon/:name/evolutions", c.Evolutions)
+	app.Get("/species/:name", c.Species)
+	app.Post("/fight", c.Auth.Require(middleware.RoleUser), c.Fight)
+	app.Post("/battle", c.Auth.Require(middleware.RoleUser), c.Battle)
+	app.Post("/team-fight", c.Auth.Require(middleware.RoleUser), c.TeamFight)
 	app.Get("/fight/history", c.GetHistories)
-	app.Put("/cancel", c.CancelPokemon)
+	app.Post("/fight/:id/replay", c.Auth.Require(middleware.RoleUser), c.Replay)
+	app.Get("/fight/:id/audit", c.Audit)
+	app.Put("/cancel", c.Auth.Require(middleware.RoleAdmin), c.CancelPokemon)
+	app.Post("/cancel/:id/revert", c.Auth.Require(middleware.RoleAdmin), c.RevertCancellation)
 	app.Get("/leaderboard", c.Leaderboard)
+	app.Post("/leaderboard/recompute", c.Auth.Require(middleware.RoleAdmin), c.RecomputeLeaderboard)
 }
 
 func (c PokeController) GetAll(ctx *fiber.Ctx) error {
@@ -49,7 +63,12 @@ func (c PokeController) GetAll(ctx *fiber.Ctx) error {
 
 func (c PokeController) GetOne(ctx *fiber.Ctx) error {
 	name := ctx.Params("name")
-	pokeData, err := c.PokeService.GetPokemonData(name)
+	pokeData, err := c.PokeService.GetPokemonDetail(name, ctx.Query("cp_formula"))
+	if errors.Is(err, service.ErrInvalidCPFormula) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
 	if err != nil {
 		return ctx.Status(404).JSON(model.Response{
 			Error: "Data Pokemon Tidak Ditemukan",
@@ -61,6 +80,47 @@ func (c PokeController) GetOne(ctx *fiber.Ctx) error {
 	})
 }
 
+func (c PokeController) Evolutions(ctx *fiber.Ctx) error {
+	evolutionData, err := c.PokeService.GetEvolutions(ctx.Params("name"), ctx.Query("cp_formula"))
+	if errors.Is(err, service.ErrInvalidCPFormula) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Evolusi Tidak Ditemukan",
+		})
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: evolutionData,
+	})
+}
+
+func (c PokeController) Species(ctx *fiber.Ctx) error {
+	speciesData, err := c.PokeService.GetSpecies(ctx.Params("name"))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Species Tidak Ditemukan",
+		})
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: speciesData,
+	})
+}
+
 func (c PokeController) Fight(ctx *fiber.Ctx) error {
 	var reqBody model.PokemonCreateReqBody
 	if err := ctx.BodyParser(&reqBody); err != nil {
@@ -69,47 +129,288 @@ func (c PokeController) Fight(ctx *fiber.Ctx) error {
 		})
 	}
 
-	if helper.HasDuplicateString(reqBody.Pokemon) {
+	pokeData, err := c.PokeService.FightPokemon(reqBody)
+	if err != nil {
+		return fightErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: pokeData,
+	})
+}
+
+func (c PokeController) Battle(ctx *fiber.Ctx) error {
+	var reqBody model.BattleReqBody
+	if err := ctx.BodyParser(&reqBody); err != nil {
 		return ctx.Status(400).JSON(model.Response{
 			Error: "Bad Request",
 		})
 	}
 
-	pokeData, err := c.PokeService.FightPokemon(reqBody.Pokemon)
+	battleData, err := c.PokeService.Battle(reqBody)
 	if err != nil {
+		return fightErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: battleData,
+	})
+}
+
+func (c PokeController) TeamFight(ctx *fiber.Ctx) error {
+	var reqBody model.TeamFightReqBody
+	if err := ctx.BodyParser(&reqBody); err != nil {
 		return ctx.Status(400).JSON(model.Response{
 			Error: "Bad Request",
 		})
 	}
 
+	teamFightData, err := c.PokeService.TeamFight(reqBody)
+	if err != nil {
+		return fightErrorResponse(ctx, err)
+	}
+
 	return ctx.Status(http.StatusOK).JSON(model.Response{
-		Data: pokeData,
+		Data: teamFightData,
+	})
+}
+
+func (c PokeController) Replay(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	replayData, err := c.PokeService.ReplayFight(uint(id))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Fight Tidak Ditemukan",
+		})
+	}
+	var participantErr *model.ParticipantError
+	if errors.As(err, &participantErr) {
+		return fightErrorResponse(ctx, err)
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: replayData,
 	})
 }
 
 func (c PokeController) GetHistories(ctx *fiber.Ctx) error {
-	var req model.PokemonReqQuery
+	req, err := historyQuery(ctx)
+	if err != nil {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+
+	fightHistories, page, err := c.PokeService.FightHistories(req)
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.ResponseCursor{
+		Data:       fightHistories,
+		NextCursor: page.NextCursor,
+		DataTotal:  page.Total,
+	})
+}
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+func historyQuery(ctx *fiber.Ctx) (model.PokemonReqQuery, error) {
+	req := model.PokemonReqQuery{
+		Sort:  model.HistorySortNewest,
+		Limit: defaultHistoryLimit,
+	}
 	if ctx.Query("start_date") != "" && ctx.Query("end_date") != "" {
-		req = model.PokemonReqQuery{
-			StartDate: fmt.Sprintf("%s 00:00:00", ctx.Query("start_date")),
-			EndDate:   fmt.Sprintf("%s 23:59:59", ctx.Query("end_date")),
+		req.StartDate = fmt.Sprintf("%s 00:00:00", ctx.Query("start_date"))
+		req.EndDate = fmt.Sprintf("%s 23:59:59", ctx.Query("end_date"))
+	}
+
+	if name := ctx.Query("pokemon"); name != "" {
+		pokemonName, ok := helper.NormalizePokemonName(name)
+		if !ok {
+			return req, fmt.Errorf("invalid pokemon %q", name)
+		}
+		req.Pokemon = pokemonName
+	}
+
+	if sort := ctx.Query("sort"); sort != "" {
+		if sort != model.HistorySortNewest && sort != model.HistorySortOldest {
+			return req, fmt.Errorf("sort must be %s or %s", model.HistorySortNewest, model.HistorySortOldest)
+		}
+		req.Sort = sort
+	}
+
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		id, ok := helper.DecodeCursor(cursor)
+		if !ok {
+			return req, fmt.Errorf("invalid cursor")
 		}
+		req.Cursor = id
+	}
+
+	if limit := ctx.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxHistoryLimit {
+			return req, fmt.Errorf("limit must be between 1 and %d", maxHistoryLimit)
+		}
+		req.Limit = n
+	}
+
+	if participants := ctx.Query("participants"); participants != "" {
+		n, err := strconv.Atoi(participants)
+		if err != nil || n < 1 {
+			return req, fmt.Errorf("participants must be a positive number")
+		}
+		req.Participants = n
+	}
+
+	if minScore := ctx.Query("min_score"); minScore != "" {
+		n, err := strconv.ParseFloat(minScore, 64)
+		if err != nil {
+			return req, fmt.Errorf("min_score must be a number")
+		}
+		req.MinScore = &n
+	}
+
+	if cancelled := ctx.Query("cancelled"); cancelled != "" {
+		b, err := strconv.ParseBool(cancelled)
+		if err != nil {
+			return req, fmt.Errorf("cancelled must be true or false")
+		}
+		req.Cancelled = &b
+	}
+
+	return req, nil
+}
+
+func (c PokeController) Leaderboard(ctx *fiber.Ctx) error {
+	req, err := leaderboardQuery(ctx, time.Now())
+	if err != nil {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
 	}
 
-	fightHistories, err := c.PokeService.FightHistories(req)
+	leaderboardData, total, err := c.PokeService.GetLeaderboard(req)
 	if err != nil {
 		return ctx.Status(500).JSON(model.Response{
 			Error: "Internal Server Error",
 		})
 	}
 
-	return ctx.Status(http.StatusOK).JSON(model.Response{
-		Data: fightHistories,
+	return ctx.Status(http.StatusOK).JSON(model.ResponseOffset{
+		Offset:    req.Offset,
+		Limit:     req.Limit,
+		Data:      leaderboardData,
+		DataTotal: total,
 	})
 }
 
-func (c PokeController) Leaderboard(ctx *fiber.Ctx) error {
-	leaderboardData, err := c.PokeService.GetLeaderboard()
+func leaderboardQuery(ctx *fiber.Ctx, now time.Time) (model.LeaderboardReqQuery, error) {
+	var req model.LeaderboardReqQuery
+	if startDate := ctx.Query("start_date"); startDate != "" {
+		if _, err := time.Parse("2006-01-02", startDate); err != nil {
+			return req, fmt.Errorf("start_date must be YYYY-MM-DD")
+		}
+		req.StartDate = fmt.Sprintf("%s 00:00:00", startDate)
+	}
+	if endDate := ctx.Query("end_date"); endDate != "" {
+		if _, err := time.Parse("2006-01-02", endDate); err != nil {
+			return req, fmt.Errorf("end_date must be YYYY-MM-DD")
+		}
+		req.EndDate = fmt.Sprintf("%s 23:59:59", endDate)
+	}
+
+	if days := ctx.Query("days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return req, fmt.Errorf("days must be a positive number")
+		}
+		if req.StartDate != "" || req.EndDate != "" {
+			return req, fmt.Errorf("days cannot be combined with start_date or end_date")
+		}
+		req.StartDate = now.AddDate(0, 0, -n).Format("2006-01-02 15:04:05")
+	}
+
+	if season := ctx.Query("season_id"); season != "" {
+		id, err := strconv.Atoi(season)
+		if err != nil || id < 1 {
+			return req, fmt.Errorf("season_id must be a positive number")
+		}
+		seasonID := uint(id)
+		req.SeasonID = &seasonID
+	}
+
+	offset, limit, err := pageQuery(ctx)
+	if err != nil {
+		return req, err
+	}
+	req.Offset, req.Limit = offset, limit
+
+	return req, nil
+}
+
+// pageQuery reads limit and offset, or top as a shorthand for the first N rows.
+func pageQuery(ctx *fiber.Ctx) (int, int, error) {
+	var offset, limit int
+	limitQuery := ctx.Query("limit")
+	if top := ctx.Query("top"); top != "" {
+		if limitQuery != "" || ctx.Query("offset") != "" {
+			return 0, 0, fmt.Errorf("top cannot be combined with limit or offset")
+		}
+		limitQuery = top
+	}
+	if limitQuery != "" {
+		n, err := strconv.Atoi(limitQuery)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("limit must be a positive number")
+		}
+		limit = n
+	}
+
+	if offsetQuery := ctx.Query("offset"); offsetQuery != "" {
+		n, err := strconv.Atoi(offsetQuery)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("offset must not be negative")
+		}
+		offset = n
+	}
+
+	return offset, limit, nil
+}
+
+func (c PokeController) RecomputeLeaderboard(ctx *fiber.Ctx) error {
+	var reqBody model.RescoreReqBody
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&reqBody); err != nil {
+			return ctx.Status(400).JSON(model.Response{
+				Error: "Bad Request",
+			})
+		}
+	}
+
+	rescored, err := c.PokeService.RescoreFights(reqBody)
+	if errors.Is(err, service.ErrInvalidScoringRule) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
 	if err != nil {
 		return ctx.Status(500).JSON(model.Response{
 			Error: "Internal Server Error",
@@ -117,7 +418,9 @@ func (c PokeController) Leaderboard(ctx *fiber.Ctx) error {
 	}
 
 	return ctx.Status(http.StatusOK).JSON(model.Response{
-		Data: leaderboardData,
+		Data: fiber.Map{
+			"rescored_fights": rescored,
+		},
 	})
 }
 
@@ -129,14 +432,138 @@ func (c PokeController) CancelPokemon(ctx *fiber.Ctx) error {
 		})
 	}
 
+	reqBody.Actor = auditActor(ctx)
+
 	pokeData, err := c.PokeService.CancelPokemon(reqBody)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Fight Tidak Ditemukan",
+		})
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		return ctx.Status(409).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
 	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: pokeData,
+	})
+}
+
+func (c PokeController) RevertCancellation(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
 		return ctx.Status(400).JSON(model.Response{
 			Error: "Bad Request",
 		})
 	}
 
+	var reqBody model.CancelRevertReqBody
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&reqBody); err != nil {
+			return ctx.Status(400).JSON(model.Response{
+				Error: "Bad Request",
+			})
+		}
+	}
+
+	reqBody.Actor = auditActor(ctx)
+
+	auditData, err := c.PokeService.RevertCancellation(uint(id), reqBody)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Cancel Tidak Ditemukan",
+		})
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		return ctx.Status(409).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
 	return ctx.Status(http.StatusOK).JSON(model.Response{
-		Data: pokeData,
+		Data: auditData,
+	})
+}
+
+// auditActor is the caller recorded in an audit entry. It always comes from
+// the authenticated principal, never from the request body.
+func auditActor(ctx *fiber.Ctx) string {
+	if principal, ok := middleware.GetPrincipal(ctx); ok {
+		return principal.Subject
+	}
+	return anonymousActor
+}
+
+const anonymousActor = "anonymous"
+
+func (c PokeController) Audit(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	auditData, err := c.PokeService.FightAudit(uint(id))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Fight Tidak Ditemukan",
+		})
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: auditData,
+	})
+}
+
+// fightErrorResponse maps the errors of fights, battles and team fights:
+// invalid participants and options are the client's fault, an unreachable
+// PokeAPI is upstream's and anything else is ours.
+func fightErrorResponse(ctx *fiber.Ctx, err error) error {
+	var participantErr *model.ParticipantError
+	if errors.As(err, &participantErr) {
+		status := 400
+		if participantErr.Upstream() {
+			status = 502
+		}
+		return ctx.Status(status).JSON(model.Response{
+			Error: participantErr,
+		})
+	}
+	if errors.Is(err, service.ErrInvalidCPFormula) || errors.Is(err, service.ErrInvalidScoringRule) || errors.Is(err, service.ErrInvalidFightMode) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		return ctx.Status(409).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+
+	return ctx.Status(500).JSON(model.Response{
+		Error: "Internal Server Error",
 	})
 }
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"pokeapi/middleware"
+	"pokeapi/model"
+	"pokeapi/service"
+)
+
+type RatingController struct {
+	RatingService service.RatingService
+	Auth          middleware.Auth
+}
+
+func NewRatingController(ratingService *service.RatingService, auth *middleware.Auth) RatingController {
+	return RatingController{
+		RatingService: *ratingService,
+		Auth:          *auth,
+	}
+}
+
+func (c RatingController) Route(app fiber.Router) {
+	app.Get("/ratings", c.GetAll)
+	app.Post("/ratings/recompute", c.Auth.Require(middleware.RoleAdmin), c.Recompute)
+	app.Get("/pokemon/:name/rating-history", c.History)
+}
+
+func (c RatingController) GetAll(ctx *fiber.Ctx) error {
+	offset, limit, err := pageQuery(ctx)
+	if err != nil {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+
+	ratings, total, err := c.RatingService.GetRatings(ctx.Query("system"), offset, limit)
+	if errors.Is(err, service.ErrInvalidRatingSystem) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.ResponseOffset{
+		Offset:    offset,
+		Limit:     limit,
+		Data:      ratings,
+		DataTotal: int(total),
+	})
+}
+
+func (c RatingController) History(ctx *fiber.Ctx) error {
+	ratingHistories, err := c.RatingService.GetRatingHistory(ctx.Params("name"))
+	if errors.Is(err, service.ErrInvalidPokemonName) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: ratingHistories,
+	})
+}
+
+func (c RatingController) Recompute(ctx *fiber.Ctx) error {
+	rated, err := c.RatingService.Recompute()
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: fiber.Map{
+			"rated_fights": rated,
+		},
+	})
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"pokeapi/middleware"
+	"pokeapi/model"
+	"pokeapi/repository"
+	"pokeapi/service"
+)
+
+type SeasonController struct {
+	SeasonService service.SeasonService
+	Auth          middleware.Auth
+}
+
+func NewSeasonController(seasonService *service.SeasonService, auth *middleware.Auth) SeasonController {
+	return SeasonController{
+		SeasonService: *seasonService,
+		Auth:          *auth,
+	}
+}
+
+func (c SeasonController) Route(app fiber.Router) {
+	app.Get("/seasons", c.GetAll)
+	app.Post("/seasons", c.Auth.Require(middleware.RoleAdmin), c.Open)
+	app.Post("/seasons/:id/close", c.Auth.Require(middleware.RoleAdmin), c.Close)
+	app.Get("/seasons/:id/leaderboard", c.Leaderboard)
+}
+
+func (c SeasonController) GetAll(ctx *fiber.Ctx) error {
+	seasons, err := c.SeasonService.GetSeasons()
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: seasons,
+	})
+}
+
+func (c SeasonController) Open(ctx *fiber.Ctx) error {
+	var reqBody model.SeasonCreateReqBody
+	if err := ctx.BodyParser(&reqBody); err != nil {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	season, err := c.SeasonService.Open(reqBody)
+	if errors.Is(err, repository.ErrConflict) {
+		return ctx.Status(409).JSON(model.Response{
+			Error: "Season Sebelumnya Belum Ditutup",
+		})
+	}
+	if errors.Is(err, service.ErrInvalidSeason) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: season,
+	})
+}
+
+func (c SeasonController) Close(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	season, err := c.SeasonService.Close(uint(id))
+	if err != nil {
+		return seasonErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: season,
+	})
+}
+
+func (c SeasonController) Leaderboard(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	offset, limit, err := pageQuery(ctx)
+	if err != nil {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+
+	leaderboardData, total, err := c.SeasonService.GetLeaderboard(uint(id), offset, limit)
+	if err != nil {
+		return seasonErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.ResponseOffset{
+		Offset:    offset,
+		Limit:     limit,
+		Data:      leaderboardData,
+		DataTotal: total,
+	})
+}
+
+func seasonErrorResponse(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Season Tidak Ditemukan",
+		})
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		return ctx.Status(409).JSON(model.Response{
+			Error: "Season Sudah Ditutup",
+		})
+	}
+	return ctx.Status(500).JSON(model.Response{
+		Error: "Internal Server Error",
+	})
+}
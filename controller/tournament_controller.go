@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"pokeapi/middleware"
+	"pokeapi/model"
+	"pokeapi/repository"
+	"pokeapi/service"
+)
+
+type TournamentController struct {
+	TournamentService service.TournamentService
+	Auth              middleware.Auth
+}
+
+func NewTournamentController(tournamentService *service.TournamentService, auth *middleware.Auth) TournamentController {
+	return TournamentController{
+		TournamentService: *tournamentService,
+		Auth:              *auth,
+	}
+}
+
+func (c TournamentController) Route(app fiber.Router) {
+	app.Get("/tournaments", c.GetAll)
+	app.Post("/tournaments", c.Auth.Require(middleware.RoleUser), c.Create)
+	app.Get("/tournaments/:id", c.GetOne)
+	app.Post("/tournaments/:id/entrants", c.Auth.Require(middleware.RoleUser), c.AddEntrants)
+	app.Post("/tournaments/:id/start", c.Auth.Require(middleware.RoleUser), c.Start)
+	app.Post("/tournaments/:id/play", c.Auth.Require(middleware.RoleUser), c.Play)
+}
+
+func (c TournamentController) GetAll(ctx *fiber.Ctx) error {
+	tournaments, err := c.TournamentService.GetTournaments()
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: tournaments,
+	})
+}
+
+func (c TournamentController) Create(ctx *fiber.Ctx) error {
+	var reqBody model.TournamentCreateReqBody
+	if err := ctx.BodyParser(&reqBody); err != nil {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	tournament, err := c.TournamentService.Create(reqBody)
+	if err != nil {
+		return tournamentErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: tournament,
+	})
+}
+
+func (c TournamentController) GetOne(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	tournament, standings, err := c.TournamentService.GetTournament(uint(id))
+	if err != nil {
+		return tournamentErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: fiber.Map{
+			"tournament": tournament,
+			"standings":  standings,
+		},
+	})
+}
+
+func (c TournamentController) AddEntrants(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	var reqBody model.TournamentEntrantReqBody
+	if err := ctx.BodyParser(&reqBody); err != nil {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	tournament, err := c.TournamentService.AddEntrants(uint(id), reqBody)
+	if err != nil {
+		return tournamentErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: tournament,
+	})
+}
+
+func (c TournamentController) Start(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	tournament, err := c.TournamentService.Start(uint(id))
+	if err != nil {
+		return tournamentErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: tournament,
+	})
+}
+
+func (c TournamentController) Play(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	var reqBody model.TournamentPlayReqBody
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&reqBody); err != nil {
+			return ctx.Status(400).JSON(model.Response{
+				Error: "Bad Request",
+			})
+		}
+	}
+
+	tournament, err := c.TournamentService.Play(uint(id), reqBody)
+	if err != nil {
+		return tournamentErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: tournament,
+	})
+}
+
+func tournamentErrorResponse(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Tournament Tidak Ditemukan",
+		})
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		return ctx.Status(409).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+
+	var participantErr *model.ParticipantError
+	if errors.As(err, &participantErr) {
+		status := 400
+		if participantErr.Upstream() {
+			status = 502
+		}
+		return ctx.Status(status).JSON(model.Response{
+			Error: participantErr,
+		})
+	}
+	if errors.Is(err, service.ErrInvalidTournament) || errors.Is(err, service.ErrInvalidScoringRule) || errors.Is(err, service.ErrInvalidFightMode) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+
+	return ctx.Status(500).JSON(model.Response{
+		Error: "Internal Server Error",
+	})
+}
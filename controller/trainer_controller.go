@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"pokeapi/middleware"
+	"pokeapi/model"
+	"pokeapi/repository"
+	"pokeapi/service"
+)
+
+type TrainerController struct {
+	TrainerService service.TrainerService
+	Auth           middleware.Auth
+}
+
+func NewTrainerController(trainerService *service.TrainerService, auth *middleware.Auth) TrainerController {
+	return TrainerController{
+		TrainerService: *trainerService,
+		Auth:           *auth,
+	}
+}
+
+func (c TrainerController) Route(app fiber.Router) {
+	app.Post("/trainers", c.Auth.Require(middleware.RoleUser), c.Register)
+	app.Get("/trainers/:id", c.GetOne)
+	app.Get("/trainers/:id/fights", c.GetFights)
+	app.Get("/leaderboard/trainers", c.Leaderboard)
+	app.Get("/leaderboard/teams", c.TeamLeaderboard)
+}
+
+func (c TrainerController) Register(ctx *fiber.Ctx) error {
+	var reqBody model.TrainerCreateReqBody
+	if err := ctx.BodyParser(&reqBody); err != nil {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	trainerData, err := c.TrainerService.Register(reqBody)
+	if errors.Is(err, repository.ErrConflict) {
+		return ctx.Status(409).JSON(model.Response{
+			Error: "Nama Trainer Sudah Terdaftar",
+		})
+	}
+	if errors.Is(err, service.ErrInvalidTrainer) {
+		return ctx.Status(400).JSON(model.Response{
+			Error: err.Error(),
+		})
+	}
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: trainerData,
+	})
+}
+
+func (c TrainerController) GetOne(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	trainerData, err := c.TrainerService.GetTrainer(uint(id))
+	if err != nil {
+		return trainerErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: trainerData,
+	})
+}
+
+func (c TrainerController) GetFights(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(400).JSON(model.Response{
+			Error: "Bad Request",
+		})
+	}
+
+	fightHistories, err := c.TrainerService.TrainerFights(uint(id))
+	if err != nil {
+		return trainerErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: fightHistories,
+	})
+}
+
+func (c TrainerController) Leaderboard(ctx *fiber.Ctx) error {
+	leaderboardData, err := c.TrainerService.GetLeaderboard()
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: leaderboardData,
+	})
+}
+
+func (c TrainerController) TeamLeaderboard(ctx *fiber.Ctx) error {
+	leaderboardData, err := c.TrainerService.GetTeamLeaderboard()
+	if err != nil {
+		return ctx.Status(500).JSON(model.Response{
+			Error: "Internal Server Error",
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(model.Response{
+		Data: leaderboardData,
+	})
+}
+
+func trainerErrorResponse(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx.Status(404).JSON(model.Response{
+			Error: "Data Trainer Tidak Ditemukan",
+		})
+	}
+
+	return ctx.Status(500).JSON(model.Response{
+		Error: "Internal Server Error",
+	})
+}
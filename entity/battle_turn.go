@@ -0,0 +1,15 @@
+package entity
+
+type BattleTurn struct {
+	ID             uint    `json:"id" gorm:"primarykey"`
+	FightHistoryID uint    `json:"id_fight_history" gorm:"index"`
+	Turn           int     `json:"turn"`
+	Attacker       string  `json:"attacker"`
+	Defender       string  `json:"defender"`
+	Move           string  `json:"move,omitempty" gorm:"size:100"`
+	Damage         int     `json:"damage"`
+	Effectiveness  float64 `json:"effectiveness"`
+	Critical       bool    `json:"critical"`
+	Missed         bool    `json:"missed"`
+	DefenderHP     int     `json:"defender_hp"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+import (
+	"time"
+)
+
+type CacheEntry struct {
+	Key       string    `json:"key" gorm:"primarykey;size:191"`
+	Value     []byte    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
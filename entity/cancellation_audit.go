@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+)
+
+// CancellationAudit rows are only ever inserted. A revert is a new row whose
+// RevertsID points at the cancellation it undid.
+type CancellationAudit struct {
+	ID             uint                      `json:"id" gorm:"primarykey"`
+	FightHistoryID uint                      `json:"id_fight_history" gorm:"index"`
+	Action         string                    `json:"action" gorm:"size:20"`
+	Pokemon        string                    `json:"pokemon"`
+	Actor          string                    `json:"actor"`
+	Reason         string                    `json:"reason"`
+	RevertsID      *uint                     `json:"reverts_id" gorm:"index"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	Changes        []CancellationAuditChange `json:"changes" gorm:"foreignKey:CancellationAuditID"`
+}
+
+type CancellationAuditChange struct {
+	ID                   uint    `json:"id" gorm:"primarykey"`
+	CancellationAuditID  uint    `json:"id_cancellation_audit" gorm:"index"`
+	FightHistoryDetailID uint    `json:"id_fight_history_detail"`
+	Pokemon              string  `json:"pokemon"`
+	RankBefore           int     `json:"rank_before"`
+	RankAfter            int     `json:"rank_after"`
+	ScoreBefore          float64 `json:"score_before" gorm:"type:decimal(10,2)"`
+	ScoreAfter           float64 `json:"score_after" gorm:"type:decimal(10,2)"`
+	CancelledBefore      bool    `json:"cancelled_before"`
+	CancelledAfter       bool    `json:"cancelled_after"`
+}
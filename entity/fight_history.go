@@ -8,5 +8,17 @@ type FightHistory struct {
 	ID                 uint                 `json:"id" gorm:"primarykey"`
 	CreatedAt          time.Time            `json:"created_at"`
 	UpdatedAt          time.Time            `json:"updated_at"`
+	TrainerID          *uint                `json:"trainer_id" gorm:"index"`
+	SeasonID           *uint                `json:"season_id" gorm:"index"`
+	Mode               string               `json:"mode" gorm:"size:20;default:cp"`
+	Seed               int64                `json:"seed"`
+	EngineVersion      string               `json:"engine_version" gorm:"size:20"`
+	ScoringRule        string               `json:"scoring_rule" gorm:"size:100;default:linear"`
+	CPFormula          string               `json:"cp_formula,omitempty" gorm:"size:150"`
+	TieBreakers        string               `json:"tie_breakers,omitempty" gorm:"size:100"`
+	TeamResolution     string               `json:"team_resolution,omitempty" gorm:"size:20"`
+	BestOf             int                  `json:"best_of,omitempty"`
 	FightHistoryDetail []FightHistoryDetail `json:"fight_history_detail" gorm:"foreignKey:FightHistoryID"`
+	BattleTurns        []BattleTurn         `json:"battle_turns,omitempty" gorm:"foreignKey:FightHistoryID"`
+	Teams              []FightTeam          `json:"teams,omitempty" gorm:"foreignKey:FightHistoryID"`
 }
@@ -1,8 +1,28 @@
 package entity
 
 type FightHistoryDetail struct {
-	ID             uint   `json:"id" gorm:"primarykey"`
-	FightHistoryID uint   `json:"id_fight_history" gorm:"foreignKey:FightHistoryID"`
-	Pokemon        string `json:"pokemon"`
-	Score          int    `json:"score"`
+	ID             uint       `json:"id" gorm:"primarykey"`
+	FightHistoryID uint       `json:"id_fight_history" gorm:"foreignKey:FightHistoryID"`
+	TrainerID      *uint      `json:"trainer_id" gorm:"index"`
+	Team           *int       `json:"team,omitempty"`
+	Pokemon        string     `json:"pokemon"`
+	Slot           int        `json:"slot"`
+	Rank           int        `json:"rank"`
+	Score          float64    `json:"score" gorm:"type:decimal(10,2)"`
+	Cancelled      bool       `json:"cancelled"`
+	Level          int        `json:"level"`
+	Nature         string     `json:"nature" gorm:"size:20"`
+	IVs            StatValues `json:"ivs" gorm:"embedded;embeddedPrefix:iv_"`
+	EVs            StatValues `json:"evs" gorm:"embedded;embeddedPrefix:ev_"`
+	Stats          StatValues `json:"stats" gorm:"embedded;embeddedPrefix:stat_"`
+}
+
+// StatValues holds one value per stat, e.g. the final stats of a participant.
+type StatValues struct {
+	HP             int `json:"hp"`
+	Attack         int `json:"attack"`
+	Defense        int `json:"defense"`
+	SpecialAttack  int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed          int `json:"speed"`
 }
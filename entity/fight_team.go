@@ -0,0 +1,14 @@
+package entity
+
+type FightTeam struct {
+	ID             uint              `json:"id" gorm:"primarykey"`
+	FightHistoryID uint              `json:"id_fight_history" gorm:"index"`
+	Team           int               `json:"team"`
+	TrainerID      *uint             `json:"trainer_id" gorm:"index"`
+	Rank           int               `json:"rank"`
+	Score          float64           `json:"score" gorm:"type:decimal(10,2)"`
+	Power          float64           `json:"power"`
+	MatchWins      int               `json:"match_wins"`
+	DuelWins       int               `json:"duel_wins"`
+	Members        []FightTeamMember `json:"members" gorm:"foreignKey:FightTeamID"`
+}
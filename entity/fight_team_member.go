@@ -0,0 +1,13 @@
+package entity
+
+type FightTeamMember struct {
+	ID          uint    `json:"id" gorm:"primarykey"`
+	FightTeamID uint    `json:"id_fight_team" gorm:"index"`
+	Pokemon     string  `json:"pokemon" gorm:"size:100;index"`
+	Slot        int     `json:"slot"`
+	CombatPower float64 `json:"combat_power"`
+	Duels       int     `json:"duels"`
+	Wins        int     `json:"wins"`
+	Losses      int     `json:"losses"`
+	DamageDealt int     `json:"damage_dealt"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+)
+
+type IdempotencyKey struct {
+	Key          string    `json:"key" gorm:"primarykey;size:191"`
+	Fingerprint  string    `json:"fingerprint" gorm:"size:64"`
+	StatusCode   int       `json:"status_code"`
+	ContentType  string    `json:"content_type" gorm:"size:100"`
+	ResponseBody []byte    `json:"-"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
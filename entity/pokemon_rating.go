@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+)
+
+type PokemonRating struct {
+	Pokemon           string    `json:"pokemon" gorm:"primarykey;size:100"`
+	Elo               float64   `json:"elo" gorm:"index"`
+	Glicko2Rating     float64   `json:"glicko2_rating" gorm:"index"`
+	Glicko2Deviation  float64   `json:"glicko2_deviation"`
+	Glicko2Volatility float64   `json:"glicko2_volatility"`
+	Fights            int       `json:"fights"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+)
+
+type RatingHistory struct {
+	ID                  uint      `json:"id" gorm:"primarykey"`
+	FightHistoryID      uint      `json:"fight_history_id" gorm:"index"`
+	Pokemon             string    `json:"pokemon" gorm:"size:100;index"`
+	Rank                int       `json:"rank"`
+	EloBefore           float64   `json:"elo_before"`
+	EloAfter            float64   `json:"elo_after"`
+	Glicko2RatingBefore float64   `json:"glicko2_rating_before"`
+	Glicko2RatingAfter  float64   `json:"glicko2_rating_after"`
+	Glicko2Deviation    float64   `json:"glicko2_deviation"`
+	Glicko2Volatility   float64   `json:"glicko2_volatility"`
+	CreatedAt           time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+)
+
+type Season struct {
+	ID          uint             `json:"id" gorm:"primarykey"`
+	Name        string           `json:"name" gorm:"size:100"`
+	ScoringRule string           `json:"scoring_rule" gorm:"size:100"`
+	StartsAt    time.Time        `json:"starts_at"`
+	EndsAt      *time.Time       `json:"ends_at"`
+	ClosedAt    *time.Time       `json:"closed_at" gorm:"index"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	Standings   []SeasonStanding `json:"-" gorm:"foreignKey:SeasonID"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+type SeasonStanding struct {
+	ID           uint    `json:"id" gorm:"primarykey"`
+	SeasonID     uint    `json:"season_id" gorm:"index"`
+	Rank         int     `json:"rank"`
+	Pokemon      string  `json:"pokemon"`
+	TotalScore   float64 `json:"total_score" gorm:"type:decimal(12,2)"`
+	Fights       int     `json:"fights"`
+	Wins         int     `json:"wins"`
+	AverageScore float64 `json:"average_score"`
+	WinRate      float64 `json:"win_rate"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+)
+
+type Tournament struct {
+	ID          uint                `json:"id" gorm:"primarykey"`
+	Name        string              `json:"name" gorm:"size:100"`
+	Format      string              `json:"format" gorm:"size:30"`
+	Rounds      int                 `json:"rounds"`
+	Seeding     string              `json:"seeding" gorm:"size:20"`
+	Mode        string              `json:"mode" gorm:"size:20"`
+	Seed        int64               `json:"seed"`
+	ScoringRule string              `json:"scoring_rule" gorm:"size:100"`
+	TrainerID   *uint               `json:"trainer_id" gorm:"index"`
+	Status      string              `json:"status" gorm:"size:20;index"`
+	Champion    string              `json:"champion" gorm:"size:100"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	Entrants    []TournamentEntrant `json:"entrants" gorm:"foreignKey:TournamentID"`
+	Matches     []TournamentMatch   `json:"matches" gorm:"foreignKey:TournamentID"`
+}
@@ -0,0 +1,9 @@
+package entity
+
+type TournamentEntrant struct {
+	ID           uint    `json:"id" gorm:"primarykey"`
+	TournamentID uint    `json:"tournament_id" gorm:"index"`
+	Pokemon      string  `json:"pokemon" gorm:"size:100"`
+	Seed         int     `json:"seed"`
+	SeedingValue float64 `json:"seeding_value"`
+}
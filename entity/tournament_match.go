@@ -0,0 +1,14 @@
+package entity
+
+type TournamentMatch struct {
+	ID             uint          `json:"id" gorm:"primarykey"`
+	TournamentID   uint          `json:"tournament_id" gorm:"index"`
+	Round          int           `json:"round"`
+	Position       int           `json:"position"`
+	Bracket        string        `json:"bracket" gorm:"size:20"`
+	PokemonA       string        `json:"pokemon_a" gorm:"size:100"`
+	PokemonB       string        `json:"pokemon_b" gorm:"size:100"`
+	Winner         string        `json:"winner" gorm:"size:100"`
+	FightHistoryID *uint         `json:"fight_history_id" gorm:"index"`
+	Fight          *FightHistory `json:"-" gorm:"-"`
+}
@@ -1,12 +1,51 @@
 package helper
 
-func HasDuplicateString(arr []string) bool {
-	counter := make(map[string]bool)
+import (
+	"encoding/base64"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var pokemonNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func DuplicateStrings(arr []string) []string {
+	var duplicates []string
+	counter := make(map[string]int)
 	for _, s := range arr {
-		if counter[s] {
-			return true
+		counter[s]++
+		if counter[s] == 2 {
+			duplicates = append(duplicates, s)
 		}
-		counter[s] = true
 	}
-	return false
+	return duplicates
+}
+
+// NormalizePokemonName lower-cases and trims a name the way PokeAPI spells it
+// and strips leading zeros from Pokédex numbers, e.g. " Pikachu " and "025".
+func NormalizePokemonName(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.Join(strings.Fields(name), "-")
+	if name != "" && strings.Trim(name, "0123456789") == "" {
+		name = strings.TrimLeft(name, "0")
+	}
+	return name, pokemonNamePattern.MatchString(name)
+}
+
+// EncodeCursor turns the ID of the last row of a page into an opaque cursor
+// for the next page.
+func EncodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("id:" + strconv.FormatUint(uint64(id), 10)))
+}
+
+func DecodeCursor(cursor string) (uint, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), "id:") {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(string(raw), "id:"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
 }
@@ -0,0 +1,46 @@
+package helper_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/helper"
+	"testing"
+)
+
+func TestNormalizePokemonName(t *testing.T) {
+	testTable := []struct {
+		name            string
+		expectedName    string
+		expectedIsValid bool
+	}{
+		{name: "pikachu", expectedName: "pikachu", expectedIsValid: true},
+		{name: "  Pikachu ", expectedName: "pikachu", expectedIsValid: true},
+		{name: "Mr Mime", expectedName: "mr-mime", expectedIsValid: true},
+		{name: "025", expectedName: "25", expectedIsValid: true},
+		{name: "0", expectedName: "", expectedIsValid: false},
+		{name: "", expectedName: "", expectedIsValid: false},
+		{name: "../pikachu", expectedName: "../pikachu", expectedIsValid: false},
+		{name: "pika?chu", expectedName: "pika?chu", expectedIsValid: false},
+	}
+
+	for _, test := range testTable {
+		name, ok := helper.NormalizePokemonName(test.name)
+		assert.Equal(t, test.expectedName, name)
+		assert.Equal(t, test.expectedIsValid, ok, test.name)
+	}
+}
+
+func TestDuplicateStrings(t *testing.T) {
+	assert.Nil(t, helper.DuplicateStrings([]string{"pikachu", "bulbasaur"}))
+	assert.Equal(t, []string{"pikachu"}, helper.DuplicateStrings([]string{"pikachu", "bulbasaur", "pikachu", "pikachu"}))
+}
+
+func TestCursor(t *testing.T) {
+	id, ok := helper.DecodeCursor(helper.EncodeCursor(42))
+	assert.True(t, ok)
+	assert.Equal(t, uint(42), id)
+
+	for _, cursor := range []string{"", "42", "aWQ6", "aWQ6MA", "aWQ6LTE", "!!"} {
+		_, ok = helper.DecodeCursor(cursor)
+		assert.False(t, ok, cursor)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"pokeapi/config"
 	"pokeapi/controller"
+	"pokeapi/middleware"
 	"pokeapi/repository"
 	"pokeapi/service"
 )
@@ -24,26 +25,96 @@ func main() {
 		panic(err)
 	}
 
+	pokeDataSource, err := config.NewPokeDataSource()
+	if err != nil {
+		panic(err)
+	}
+
+	cachedPokeDataSource, err := config.NewCachedPokeDataSource(pokeDataSource, db)
+	if err != nil {
+		panic(err)
+	}
+	if cachedPokeDataSource != nil {
+		pokeDataSource = cachedPokeDataSource
+	}
+
+	fightConfig, err := config.NewFightConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	authConfig, err := config.NewAuthConfig()
+	if err != nil {
+		panic(err)
+	}
+	auth := middleware.NewAuth(authConfig)
+
+	rateLimiter, readLimit, writeLimit, credentialsLimit, err := config.NewRateLimiter()
+	if err != nil {
+		panic(err)
+	}
+
+	idempotency, stopIdempotencyCleanup, err := config.NewIdempotency(db)
+	if err != nil {
+		panic(err)
+	}
+	defer stopIdempotencyCleanup()
+
 	pokeRepository := repository.NewPokeRepository(db)
-	pokeService := service.NewPokeService(&pokeRepository)
-	pokeController := controller.NewPokeController(&pokeService)
+	pokeService := service.NewPokeService(&pokeRepository, pokeDataSource, fightConfig)
+	pokeController := controller.NewPokeController(&pokeService, &auth)
+
+	trainerRepository := repository.NewTrainerRepository(db)
+	trainerService := service.NewTrainerService(&trainerRepository)
+	trainerController := controller.NewTrainerController(&trainerService, &auth)
+
+	seasonRepository := repository.NewSeasonRepository(db)
+	seasonService := service.NewSeasonService(&seasonRepository, fightConfig)
+	seasonController := controller.NewSeasonController(&seasonService, &auth)
+
+	ratingRepository := repository.NewRatingRepository(db)
+	ratingService := service.NewRatingService(&ratingRepository)
+	ratingController := controller.NewRatingController(&ratingService, &auth)
+
+	tournamentRepository := repository.NewTournamentRepository(db)
+	tournamentService := service.NewTournamentService(&tournamentRepository, &ratingRepository, &pokeService)
+	tournamentController := controller.NewTournamentController(&tournamentService, &auth)
+
+	allowOrigins := os.Getenv("CORS_ALLOW_ORIGINS")
+	if allowOrigins == "" {
+		allowOrigins = "*"
+	}
 
 	app := fiber.New()
 	app.Use(recover.New())
 	app.Use(cors.New(
 		cors.Config{
 			Next:             nil,
-			AllowOrigins:     "*",
+			AllowOrigins:     allowOrigins,
 			AllowMethods:     "OPTIONS,GET,POST,HEAD,PUT,DELETE,PATCH",
 			AllowHeaders:     "",
 			AllowCredentials: false,
-			ExposeHeaders:    "",
+			ExposeHeaders:    "X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,Retry-After,Idempotent-Replayed",
 			MaxAge:           0,
 		},
 	))
+	app.Use(rateLimiter.Credentials(credentialsLimit))
+	app.Use(auth.Authenticate())
+	app.Use(rateLimiter.ByMethod(readLimit, writeLimit))
+	app.Use(idempotency.Handler())
 
 	v1 := app.Group("/")
 	pokeController.Route(v1)
+	trainerController.Route(v1)
+	seasonController.Route(v1)
+	ratingController.Route(v1)
+	tournamentController.Route(v1)
+
+	if cachedPokeDataSource != nil {
+		cacheService := service.NewCacheService(cachedPokeDataSource)
+		cacheController := controller.NewCacheController(&cacheService, &auth)
+		cacheController.Route(v1)
+	}
 
 	host := os.Getenv("HOST")
 	port := os.Getenv("PORT")
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/gofiber/fiber/v2"
+	"pokeapi/model"
+	"strings"
+	"time"
+)
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+
+	principalKey = "principal"
+)
+
+type Principal struct {
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles"`
+	Method  string   `json:"method"`
+}
+
+func (p Principal) HasRole(role string) bool {
+	return containsString(p.Roles, role)
+}
+
+type APIKey struct {
+	Key       string
+	Principal Principal
+}
+
+type AuthConfig struct {
+	Disabled bool
+	APIKeys  []APIKey
+	JWT      JWTVerifier
+}
+
+type Auth struct {
+	Config AuthConfig
+}
+
+func NewAuth(config AuthConfig) Auth {
+	return Auth{
+		Config: config,
+	}
+}
+
+// Authenticate resolves the caller from an X-API-Key header or a bearer JWT
+// and stores it for Require and GetPrincipal. Requests without credentials
+// pass through anonymously; wrong credentials are rejected straight away.
+func (a Auth) Authenticate() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if key := ctx.Get("X-API-Key"); key != "" {
+			principal, ok := a.apiKeyPrincipal(key)
+			if !ok {
+				return unauthorized(ctx)
+			}
+			ctx.Locals(principalKey, principal)
+			return ctx.Next()
+		}
+
+		authorization := ctx.Get(fiber.HeaderAuthorization)
+		if token, ok := strings.CutPrefix(authorization, "Bearer "); ok && a.Config.JWT.Enabled() {
+			claims, err := a.Config.JWT.Verify(strings.TrimSpace(token), time.Now())
+			if err != nil {
+				return unauthorized(ctx)
+			}
+			roles := claims.Roles
+			if claims.Role != "" {
+				roles = append(roles, claims.Role)
+			}
+			ctx.Locals(principalKey, Principal{
+				Subject: claims.Subject,
+				Roles:   roles,
+				Method:  "jwt",
+			})
+			return ctx.Next()
+		}
+		if authorization != "" {
+			return unauthorized(ctx)
+		}
+
+		return ctx.Next()
+	}
+}
+
+// Require only lets authenticated callers through; with roles given the
+// caller needs at least one of them. An admin passes every role check.
+func (a Auth) Require(roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if a.Config.Disabled {
+			return ctx.Next()
+		}
+
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			return unauthorized(ctx)
+		}
+		if len(roles) == 0 || principal.HasRole(RoleAdmin) {
+			return ctx.Next()
+		}
+		for _, role := range roles {
+			if principal.HasRole(role) {
+				return ctx.Next()
+			}
+		}
+
+		return ctx.Status(fiber.StatusForbidden).JSON(model.Response{
+			Error: "Forbidden",
+		})
+	}
+}
+
+func GetPrincipal(ctx *fiber.Ctx) (Principal, bool) {
+	principal, ok := ctx.Locals(principalKey).(Principal)
+	return principal, ok
+}
+
+func (a Auth) apiKeyPrincipal(key string) (Principal, bool) {
+	for _, k := range a.Config.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
+			principal := k.Principal
+			principal.Method = "api_key"
+			return principal, true
+		}
+	}
+	return Principal{}, false
+}
+
+func unauthorized(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusUnauthorized).JSON(model.Response{
+		Error: "Unauthorized",
+	})
+}
@@ -0,0 +1,146 @@
+package middleware_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"pokeapi/middleware"
+	"testing"
+	"time"
+)
+
+func signToken(t *testing.T, alg string, claims map[string]any, key any) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	secret := []byte("local-test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	verifier := middleware.JWTVerifier{
+		HMACSecret:   secret,
+		RSAPublicKey: &rsaKey.PublicKey,
+		Issuer:       "poke",
+		Audience:     "poke-api",
+	}
+	now := time.Now()
+	valid := map[string]any{"sub": "ash", "iss": "poke", "aud": "poke-api", "exp": now.Add(time.Hour).Unix(), "role": "admin"}
+
+	claims, err := verifier.Verify(signToken(t, "HS256", valid, secret), now)
+	assert.NoError(t, err)
+	assert.Equal(t, "ash", claims.Subject)
+	assert.Equal(t, "admin", claims.Role)
+
+	claims, err = verifier.Verify(signToken(t, "RS256", map[string]any{"sub": "misty", "iss": "poke", "aud": []string{"other", "poke-api"}, "roles": []string{"user"}}, rsaKey), now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user"}, claims.Roles)
+
+	invalid := []string{
+		signToken(t, "HS256", valid, []byte("wrong-secret")),
+		signToken(t, "RS256", valid, otherKey),
+		signToken(t, "HS256", map[string]any{"sub": "ash", "iss": "poke", "aud": "poke-api", "exp": now.Add(-time.Minute).Unix()}, secret),
+		signToken(t, "HS256", map[string]any{"sub": "ash", "iss": "poke", "aud": "poke-api", "nbf": now.Add(time.Hour).Unix()}, secret),
+		signToken(t, "HS256", map[string]any{"sub": "ash", "iss": "team-rocket", "aud": "poke-api"}, secret),
+		signToken(t, "HS256", map[string]any{"sub": "ash", "iss": "poke", "aud": "other"}, secret),
+		signToken(t, "none", valid, nil),
+		"not-a-token",
+	}
+	for _, token := range invalid {
+		_, err := verifier.Verify(token, now)
+		assert.ErrorIs(t, err, middleware.ErrInvalidToken, token)
+	}
+
+	hmacOnly := middleware.JWTVerifier{HMACSecret: secret}
+	_, err = hmacOnly.Verify(signToken(t, "RS256", valid, rsaKey), now)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+}
+
+func TestAuthRequire(t *testing.T) {
+	secret := []byte("local-test-secret")
+	auth := middleware.NewAuth(middleware.AuthConfig{
+		APIKeys: []middleware.APIKey{
+			{Key: "user-key", Principal: middleware.Principal{Subject: "bot", Roles: []string{middleware.RoleUser}}},
+			{Key: "admin-key", Principal: middleware.Principal{Subject: "oak", Roles: []string{middleware.RoleAdmin}}},
+		},
+		JWT: middleware.JWTVerifier{HMACSecret: secret},
+	})
+
+	app := fiber.New()
+	app.Use(auth.Authenticate())
+	app.Get("/open", func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/fight", auth.Require(middleware.RoleUser), func(ctx *fiber.Ctx) error {
+		principal, _ := middleware.GetPrincipal(ctx)
+		return ctx.SendString(principal.Subject)
+	})
+	app.Put("/cancel", auth.Require(middleware.RoleAdmin), func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	userToken := signToken(t, "HS256", map[string]any{"sub": "ash", "role": "user"}, secret)
+	testTable := []struct {
+		method         string
+		path           string
+		header         string
+		value          string
+		expectedStatus int
+	}{
+		{method: "GET", path: "/open", expectedStatus: 200},
+		{method: "POST", path: "/fight", expectedStatus: 401},
+		{method: "POST", path: "/fight", header: "X-API-Key", value: "user-key", expectedStatus: 200},
+		{method: "POST", path: "/fight", header: "X-API-Key", value: "admin-key", expectedStatus: 200},
+		{method: "POST", path: "/fight", header: "X-API-Key", value: "stolen-key", expectedStatus: 401},
+		{method: "POST", path: "/fight", header: "Authorization", value: "Bearer " + userToken, expectedStatus: 200},
+		{method: "POST", path: "/fight", header: "Authorization", value: "Bearer " + userToken + "x", expectedStatus: 401},
+		{method: "PUT", path: "/cancel", header: "Authorization", value: "Bearer " + userToken, expectedStatus: 403},
+		{method: "PUT", path: "/cancel", header: "X-API-Key", value: "user-key", expectedStatus: 403},
+		{method: "PUT", path: "/cancel", header: "X-API-Key", value: "admin-key", expectedStatus: 200},
+		{method: "GET", path: "/open", header: "X-API-Key", value: "stolen-key", expectedStatus: 401},
+	}
+
+	for _, test := range testTable {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if test.header != "" {
+			req.Header.Set(test.header, test.value)
+		}
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedStatus, res.StatusCode, "%s %s %s", test.method, test.path, test.value)
+	}
+
+	disabled := middleware.NewAuth(middleware.AuthConfig{Disabled: true})
+	app = fiber.New()
+	app.Put("/cancel", disabled.Require(middleware.RoleAdmin), func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+	res, err := app.Test(httptest.NewRequest("PUT", "/cancel", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
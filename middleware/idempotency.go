@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gofiber/fiber/v2"
+	"pokeapi/model"
+	"sync"
+	"time"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyStore remembers the response to every idempotency key. Reserve
+// claims an unused or expired key with a pending record, or returns the record
+// that still holds it. Complete stores the response and the record's new
+// expiry.
+type IdempotencyStore interface {
+	Reserve(record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error)
+	Complete(record model.IdempotencyRecord) error
+	Release(key string) error
+}
+
+type MemoryIdempotencyStore struct {
+	mutex     sync.Mutex
+	records   map[string]model.IdempotencyRecord
+	lastSweep time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]model.IdempotencyRecord),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	defer s.sweep(now)
+
+	if existing, ok := s.records[record.Key]; ok && now.Before(existing.ExpiresAt) {
+		return existing, false, nil
+	}
+	s.records[record.Key] = record
+	return record, true, nil
+}
+
+// sweep drops expired records. It runs at most once a minute.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func (s *MemoryIdempotencyStore) Complete(record model.IdempotencyRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records[record.Key] = record
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.records)
+}
+
+// Idempotency keeps completed responses for TTL. A request in progress only
+// holds its key for Lease, so a key is not blocked for long when the process
+// serving it dies.
+type Idempotency struct {
+	Store IdempotencyStore
+	TTL   time.Duration
+	Lease time.Duration
+}
+
+func NewIdempotency(store IdempotencyStore, ttl time.Duration, lease time.Duration) Idempotency {
+	return Idempotency{
+		Store: store,
+		TTL:   ttl,
+		Lease: lease,
+	}
+}
+
+// Handler makes write requests carrying an Idempotency-Key safe to retry. The
+// first request runs and its response is stored; a retry with the same key and
+// the same method, path and body gets that response back, while reusing the
+// key for a different request is rejected with 422. Keys are scoped per
+// caller and server errors are not stored, so those can be retried.
+func (i Idempotency) Handler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(IdempotencyKeyHeader)
+		method := ctx.Method()
+		if key == "" || method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions {
+			return ctx.Next()
+		}
+		if len(key) > 128 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Error: "Idempotency-Key must be at most 128 characters",
+			})
+		}
+
+		caller := "ip:" + ctx.IP()
+		if principal, ok := GetPrincipal(ctx); ok {
+			caller = principal.Method + ":" + principal.Subject
+		}
+		// hashed, since a caller's subject is unbounded and the stored key is not
+		callerKey := sha256.Sum256([]byte(caller + "|" + key))
+		storeKey := hex.EncodeToString(callerKey[:])
+
+		hash := sha256.New()
+		hash.Write([]byte(method + " " + ctx.Path() + "\n"))
+		hash.Write(ctx.Body())
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		now := time.Now()
+		record, reserved, err := i.Store.Reserve(model.IdempotencyRecord{
+			Key:         storeKey,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(i.Lease),
+		}, now)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Error: "Internal Server Error",
+			})
+		}
+
+		if !reserved {
+			if record.Fingerprint != fingerprint {
+				return ctx.Status(fiber.StatusUnprocessableEntity).JSON(model.Response{
+					Error: "Idempotency-Key was already used for a different request",
+				})
+			}
+			if !record.Completed() {
+				return ctx.Status(fiber.StatusConflict).JSON(model.Response{
+					Error: "A request with this Idempotency-Key is still in progress",
+				})
+			}
+			ctx.Set("Idempotent-Replayed", "true")
+			ctx.Set(fiber.HeaderContentType, record.ContentType)
+			return ctx.Status(record.StatusCode).Send(record.Body)
+		}
+
+		if err := ctx.Next(); err != nil {
+			_ = i.Store.Release(storeKey)
+			return err
+		}
+
+		status := ctx.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			_ = i.Store.Release(storeKey)
+			return nil
+		}
+
+		record.StatusCode = status
+		record.ContentType = string(ctx.Response().Header.ContentType())
+		record.Body = append([]byte(nil), ctx.Response().Body()...)
+		record.ExpiresAt = time.Now().Add(i.TTL)
+		_ = i.Store.Complete(record)
+		return nil
+	}
+}
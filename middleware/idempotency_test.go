@@ -0,0 +1,162 @@
+package middleware_test
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"pokeapi/middleware"
+	"pokeapi/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdempotency(t *testing.T) {
+	store := middleware.NewMemoryIdempotencyStore()
+	idempotency := middleware.NewIdempotency(store, time.Hour, time.Minute)
+
+	calls := 0
+	app := fiber.New()
+	app.Use(idempotency.Handler())
+	app.Post("/fight", func(ctx *fiber.Ctx) error {
+		calls++
+		if string(ctx.Body()) == "fail" {
+			return ctx.Status(fiber.StatusBadGateway).SendString("upstream")
+		}
+		return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"call": calls})
+	})
+
+	send := func(key string, body string) (int, string, string) {
+		req := httptest.NewRequest("POST", "/fight", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		resBody, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(resBody), res.Header.Get("Idempotent-Replayed")
+	}
+
+	status, body, replayed := send("a", "pikachu")
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, `{"call":1}`, body)
+	assert.Empty(t, replayed)
+
+	status, body, replayed = send("a", "pikachu")
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, `{"call":1}`, body)
+	assert.Equal(t, "true", replayed)
+	assert.Equal(t, 1, calls)
+
+	status, _, _ = send("a", "bulbasaur")
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+
+	_, body, _ = send("", "pikachu")
+	assert.Equal(t, `{"call":2}`, body)
+
+	status, _, _ = send("b", "fail")
+	assert.Equal(t, fiber.StatusBadGateway, status)
+	status, _, replayed = send("b", "fail")
+	assert.Equal(t, fiber.StatusBadGateway, status)
+	assert.Empty(t, replayed)
+	assert.Equal(t, 4, calls)
+
+	status, _, _ = send(strings.Repeat("k", 129), "pikachu")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	store := middleware.NewMemoryIdempotencyStore()
+	now := time.Now()
+	record := model.IdempotencyRecord{Key: "ip:1|a", Fingerprint: "f", ExpiresAt: now.Add(time.Minute)}
+
+	_, reserved, err := store.Reserve(record, now)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	existing, reserved, _ := store.Reserve(record, now)
+	assert.False(t, reserved)
+	assert.False(t, existing.Completed())
+
+	_, reserved, _ = store.Reserve(record, now.Add(time.Hour))
+	assert.True(t, reserved)
+
+	// expired records are swept on a later reservation
+	other := model.IdempotencyRecord{Key: "ip:1|b", Fingerprint: "f", ExpiresAt: now.Add(3 * time.Hour)}
+	_, reserved, _ = store.Reserve(other, now.Add(2*time.Hour))
+	assert.True(t, reserved)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestIdempotencyLease(t *testing.T) {
+	store := middleware.NewMemoryIdempotencyStore()
+	idempotency := middleware.NewIdempotency(store, time.Hour, 100*time.Millisecond)
+
+	release := make(chan struct{})
+	app := fiber.New()
+	app.Use(idempotency.Handler())
+	app.Post("/fight", func(ctx *fiber.Ctx) error {
+		if string(ctx.Body()) == "stuck" {
+			<-release
+		}
+		return ctx.SendStatus(fiber.StatusCreated)
+	})
+
+	send := func(key string, body string) int {
+		req := httptest.NewRequest("POST", "/fight", strings.NewReader(body))
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		res, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		return res.StatusCode
+	}
+
+	// a request that never finishes only holds its key for the lease
+	go send("a", "stuck")
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, fiber.StatusConflict, send("a", "stuck"))
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	assert.Equal(t, fiber.StatusCreated, send("a", "stuck"))
+
+	// a completed response is kept for the TTL, well past the lease
+	assert.Equal(t, fiber.StatusCreated, send("b", "pikachu"))
+	time.Sleep(110 * time.Millisecond)
+	req := httptest.NewRequest("POST", "/fight", strings.NewReader("pikachu"))
+	req.Header.Set(middleware.IdempotencyKeyHeader, "b")
+	res, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", res.Header.Get("Idempotent-Replayed"))
+}
+
+type keyRecordingStore struct {
+	*middleware.MemoryIdempotencyStore
+	keys []string
+}
+
+func (s *keyRecordingStore) Reserve(record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error) {
+	s.keys = append(s.keys, record.Key)
+	return s.MemoryIdempotencyStore.Reserve(record, now)
+}
+
+func TestIdempotencyStoreKey(t *testing.T) {
+	store := &keyRecordingStore{MemoryIdempotencyStore: middleware.NewMemoryIdempotencyStore()}
+	app := fiber.New()
+	app.Use(middleware.NewIdempotency(store, time.Hour, time.Minute).Handler())
+	app.Post("/fight", func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusCreated)
+	})
+
+	for _, key := range []string{"a", strings.Repeat("k", 128)} {
+		req := httptest.NewRequest("POST", "/fight", strings.NewReader("pikachu"))
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		_, err := app.Test(req)
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, store.keys, 2)
+	assert.NotEqual(t, store.keys[0], store.keys[1])
+	for _, key := range store.keys {
+		assert.Regexp(t, "^[0-9a-f]{64}$", key)
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type JWTClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Role      string   `json:"role"`
+	Roles     []string `json:"roles"`
+}
+
+// audience accepts both forms allowed for "aud", a string or a list of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+type JWTVerifier struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	Issuer       string
+	Audience     string
+	Leeway       time.Duration
+}
+
+// Verify checks an HS256 or RS256 signed compact JWT. Only algorithms with a
+// configured key are accepted, so "none" and algorithm confusion both fail.
+func (v JWTVerifier) Verify(token string, now time.Time) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return JWTClaims{}, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return JWTClaims{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return JWTClaims{}, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch {
+	case header.Algorithm == "HS256" && len(v.HMACSecret) > 0:
+		mac := hmac.New(sha256.New, v.HMACSecret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return JWTClaims{}, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	case header.Algorithm == "RS256" && v.RSAPublicKey != nil:
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(v.RSAPublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return JWTClaims{}, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	default:
+		return JWTClaims{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+
+	var claims JWTClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return JWTClaims{}, err
+	}
+
+	if claims.ExpiresAt != 0 && !now.Before(time.Unix(claims.ExpiresAt, 0).Add(v.Leeway)) {
+		return JWTClaims{}, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if claims.NotBefore != 0 && now.Add(v.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return JWTClaims{}, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return JWTClaims{}, fmt.Errorf("%w: issuer", ErrInvalidToken)
+	}
+	if v.Audience != "" && !containsString(claims.Audience, v.Audience) {
+		return JWTClaims{}, fmt.Errorf("%w: audience", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+func (v JWTVerifier) Enabled() bool {
+	return len(v.HMACSecret) > 0 || v.RSAPublicKey != nil
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: segment encoding", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w: segment json", ErrInvalidToken)
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"math"
+	"pokeapi/model"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitStore keeps one token bucket per key. Take consumes a token when one
+// is available; a shared store such as Redis only has to implement this.
+type RateLimitStore interface {
+	Take(key string, limit RateLimit, now time.Time) RateLimitResult
+}
+
+type RateLimit struct {
+	Name   string
+	Rate   float64
+	Burst  int
+	Period time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time
+}
+
+type MemoryRateLimitStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, limit RateLimit, now time.Time) RateLimitResult {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	perSecond := limit.Rate / limit.Period.Seconds()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*perSecond)
+	b.updated = now
+	defer s.sweep(now)
+
+	result := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = time.Duration((float64(limit.Burst) - b.tokens) / perSecond * float64(time.Second))
+	b.fullAt = now.Add(result.ResetAfter)
+
+	return result
+}
+
+// sweep drops buckets that have refilled completely, since a fresh bucket
+// behaves the same. It runs at most once a minute.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.After(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+type RateLimiter struct {
+	Store    RateLimitStore
+	Disabled bool
+}
+
+func NewRateLimiter(store RateLimitStore, disabled bool) RateLimiter {
+	return RateLimiter{
+		Store:    store,
+		Disabled: disabled,
+	}
+}
+
+// ByMethod spends the read budget on GET and HEAD requests and the write
+// budget on everything else.
+func (l RateLimiter) ByMethod(read RateLimit, write RateLimit) fiber.Handler {
+	readLimit, writeLimit := l.Limit(read), l.Limit(write)
+	return func(ctx *fiber.Ctx) error {
+		switch ctx.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return readLimit(ctx)
+		default:
+			return writeLimit(ctx)
+		}
+	}
+}
+
+// Limit applies a token bucket per caller, keyed by the authenticated subject
+// and falling back to the client IP, with a separate bucket per limit name.
+func (l RateLimiter) Limit(limit RateLimit) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := "ip:" + ctx.IP()
+		if principal, ok := GetPrincipal(ctx); ok {
+			key = principal.Method + ":" + principal.Subject
+		}
+		return l.take(ctx, limit, key)
+	}
+}
+
+// Credentials applies a token bucket per client IP to requests presenting an
+// API key or an Authorization header. It runs before authentication, so
+// credentials cannot be guessed faster than the limit allows.
+func (l RateLimiter) Credentials(limit RateLimit) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if ctx.Get("X-API-Key") == "" && ctx.Get(fiber.HeaderAuthorization) == "" {
+			return ctx.Next()
+		}
+		return l.take(ctx, limit, "ip:"+ctx.IP())
+	}
+}
+
+func (l RateLimiter) take(ctx *fiber.Ctx, limit RateLimit, key string) error {
+	if l.Disabled || limit.Burst < 1 || limit.Rate <= 0 {
+		return ctx.Next()
+	}
+
+	result := l.Store.Take(limit.Name+"|"+key, limit, time.Now())
+	ctx.Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+	ctx.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	ctx.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+	if !result.Allowed {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(model.Response{
+			Error: "Too Many Requests",
+		})
+	}
+
+	return ctx.Next()
+}
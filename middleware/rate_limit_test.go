@@ -0,0 +1,117 @@
+package middleware_test
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"pokeapi/middleware"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := middleware.NewMemoryRateLimitStore()
+	limit := middleware.RateLimit{Name: "write", Rate: 2, Burst: 2, Period: time.Second}
+	now := time.Now()
+
+	assert.True(t, store.Take("ash", limit, now).Allowed)
+	result := store.Take("ash", limit, now)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result = store.Take("ash", limit, now)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	assert.True(t, store.Take("misty", limit, now).Allowed)
+
+	result = store.Take("ash", limit, now.Add(500*time.Millisecond))
+	assert.True(t, result.Allowed)
+	assert.False(t, store.Take("ash", limit, now.Add(500*time.Millisecond)).Allowed)
+
+	result = store.Take("ash", limit, now.Add(time.Hour))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestRateLimiterByMethod(t *testing.T) {
+	auth := middleware.NewAuth(middleware.AuthConfig{
+		APIKeys: []middleware.APIKey{
+			{Key: "user-key", Principal: middleware.Principal{Subject: "bot", Roles: []string{middleware.RoleUser}}},
+		},
+	})
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), false)
+
+	app := fiber.New()
+	app.Use(auth.Authenticate())
+	app.Use(limiter.ByMethod(
+		middleware.RateLimit{Name: "read", Rate: 3, Burst: 3, Period: time.Minute},
+		middleware.RateLimit{Name: "write", Rate: 1, Burst: 1, Period: time.Minute},
+	))
+	handler := func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	}
+	app.Get("/leaderboard", handler)
+	app.Post("/fight", handler)
+
+	send := func(method string, apiKey string) (int, string, string, string) {
+		req := httptest.NewRequest(method, map[string]string{"GET": "/leaderboard", "POST": "/fight"}[method], nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		return res.StatusCode, res.Header.Get("X-RateLimit-Limit"), res.Header.Get("X-RateLimit-Remaining"), res.Header.Get("Retry-After")
+	}
+
+	status, limit, remaining, _ := send("POST", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "1", limit)
+	assert.Equal(t, "0", remaining)
+
+	status, _, _, retryAfter := send("POST", "")
+	assert.Equal(t, 429, status)
+	assert.Equal(t, "60", retryAfter)
+
+	status, limit, remaining, _ = send("GET", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "3", limit)
+	assert.Equal(t, "2", remaining)
+
+	status, _, _, _ = send("POST", "user-key")
+	assert.Equal(t, 200, status)
+	status, _, _, _ = send("POST", "user-key")
+	assert.Equal(t, 429, status)
+}
+
+func TestRateLimiterCredentials(t *testing.T) {
+	auth := middleware.NewAuth(middleware.AuthConfig{
+		APIKeys: []middleware.APIKey{
+			{Key: "user-key", Principal: middleware.Principal{Subject: "bot", Roles: []string{middleware.RoleUser}}},
+		},
+	})
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), false)
+
+	app := fiber.New()
+	app.Use(limiter.Credentials(middleware.RateLimit{Name: "credentials", Rate: 2, Burst: 2, Period: time.Minute}))
+	app.Use(auth.Authenticate())
+	app.Get("/leaderboard", func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	send := func(header string, value string) int {
+		req := httptest.NewRequest("GET", "/leaderboard", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		return res.StatusCode
+	}
+
+	assert.Equal(t, 401, send("X-API-Key", "guess-1"))
+	assert.Equal(t, 401, send("Authorization", "Bearer guess-2"))
+	// guessing is cut off before the credentials are checked, even the right ones
+	assert.Equal(t, 429, send("X-API-Key", "user-key"))
+	// requests without credentials are left to the per-caller limits
+	assert.Equal(t, 200, send("", ""))
+}
@@ -0,0 +1,47 @@
+package model
+
+type BattleReqBody struct {
+	Pokemon        []string   `json:"pokemon"`
+	Specs          []StatSpec `json:"specs"`
+	Seed           *int64     `json:"seed"`
+	Scoring        string     `json:"scoring"`
+	TrainerID      *uint      `json:"trainer_id"`
+	TrainerPokemon []string   `json:"trainer_pokemon"`
+}
+
+type BattleResult struct {
+	FightHistoryID uint              `json:"fight_history_id"`
+	Seed           int64             `json:"seed"`
+	EngineVersion  string            `json:"engine_version"`
+	CPFormula      string            `json:"cp_formula"`
+	Winner         string            `json:"winner"`
+	Loser          string            `json:"loser"`
+	Turns          int               `json:"turns"`
+	Combatants     []BattleCombatant `json:"combatants"`
+	Log            []BattleTurn      `json:"log"`
+}
+
+type BattleCombatant struct {
+	Name        string   `json:"name"`
+	Types       []string `json:"types"`
+	Level       int      `json:"level"`
+	MaxHP       int      `json:"max_hp"`
+	RemainingHP int      `json:"remaining_hp"`
+	Attack      int      `json:"attack"`
+	Defense     int      `json:"defense"`
+	SpAttack    int      `json:"special_attack"`
+	SpDefense   int      `json:"special_defense"`
+	Speed       int      `json:"speed"`
+}
+
+type BattleTurn struct {
+	Turn          int     `json:"turn"`
+	Attacker      string  `json:"attacker"`
+	Defender      string  `json:"defender"`
+	Move          string  `json:"move,omitempty"`
+	Damage        int     `json:"damage"`
+	Effectiveness float64 `json:"effectiveness"`
+	Critical      bool    `json:"critical"`
+	Missed        bool    `json:"missed"`
+	DefenderHP    int     `json:"defender_hp"`
+}
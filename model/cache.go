@@ -0,0 +1,10 @@
+package model
+
+type CacheStats struct {
+	Entries    int     `json:"entries"`
+	Persistent bool    `json:"persistent"`
+	MemoryHits int64   `json:"memory_hits"`
+	StoreHits  int64   `json:"store_hits"`
+	Misses     int64   `json:"misses"`
+	HitRatio   float64 `json:"hit_ratio"`
+}
@@ -0,0 +1,18 @@
+package model
+
+type ParticipantError struct {
+	Message     string   `json:"message"`
+	Invalid     []string `json:"invalid,omitempty"`
+	Duplicated  []string `json:"duplicated,omitempty"`
+	Unreachable []string `json:"unreachable,omitempty"`
+}
+
+func (e *ParticipantError) Error() string {
+	return e.Message
+}
+
+// Upstream reports whether the request itself was fine and only fetching the
+// Pokémon from the data source failed.
+func (e *ParticipantError) Upstream() bool {
+	return len(e.Invalid) == 0 && len(e.Duplicated) == 0 && len(e.Unreachable) > 0
+}
@@ -1,9 +1,28 @@
 package model
 
 type Pokemon struct {
-	Name        string  `json:"name"`
-	Stats       []Stat  `json:"stats"`
-	CombatPower float64 `json:"combat_power"`
+	ID             int       `json:"id"`
+	Name           string    `json:"name"`
+	Types          []string  `json:"types"`
+	Abilities      []Ability `json:"abilities,omitempty"`
+	Moves          []Move    `json:"moves,omitempty"`
+	Stats          []Stat    `json:"stats"`
+	Spec           *StatSpec `json:"spec,omitempty"`
+	FinalStats     []Stat    `json:"final_stats,omitempty"`
+	CombatPower    float64   `json:"combat_power"`
+	EffectivePower float64   `json:"effective_power,omitempty"`
+	Rank           int       `json:"rank,omitempty"`
+	TieBreak       string    `json:"tie_break,omitempty"`
+}
+
+// StatSpec is what sets one Pokémon apart from another of its species. IVs
+// and EVs are keyed by stat name, missing stats are 0.
+type StatSpec struct {
+	Pokemon string         `json:"pokemon,omitempty"`
+	Level   int            `json:"level"`
+	Nature  string         `json:"nature"`
+	IVs     map[string]int `json:"ivs,omitempty"`
+	EVs     map[string]int `json:"evs,omitempty"`
 }
 
 type Stat struct {
@@ -11,6 +30,23 @@ type Stat struct {
 	Value int    `json:"value"`
 }
 
+type Ability struct {
+	Name   string `json:"name"`
+	Hidden bool   `json:"hidden"`
+}
+
+// Move is a move the Pokémon knows in battle. Power is 0 for moves without a
+// fixed power and Accuracy is 0 for moves that never miss.
+type Move struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	DamageClass string `json:"damage_class"`
+	Power       int    `json:"power"`
+	Accuracy    int    `json:"accuracy"`
+	PP          int    `json:"pp"`
+	Priority    int    `json:"priority"`
+}
+
 type PokeDataSourceRes struct {
 	Count   int `json:"count"`
 	Results []struct {
@@ -20,30 +56,163 @@ type PokeDataSourceRes struct {
 }
 
 type PokeDetailDataSourceRes struct {
-	Name  string `json:"name"`
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Species struct {
+		Name string `json:"name"`
+	} `json:"species"`
 	Stats []struct {
 		BaseStat int `json:"base_stat"`
 		Stat     struct {
 			Name string `json:"name"`
 		} `json:"stat"`
 	} `json:"stats"`
+	Types []struct {
+		Slot int `json:"slot"`
+		Type struct {
+			Name string `json:"name"`
+		} `json:"type"`
+	} `json:"types"`
+	Abilities []struct {
+		IsHidden bool `json:"is_hidden"`
+		Slot     int  `json:"slot"`
+		Ability  struct {
+			Name string `json:"name"`
+		} `json:"ability"`
+	} `json:"abilities"`
+	Moves []struct {
+		Move struct {
+			Name string `json:"name"`
+		} `json:"move"`
+		VersionGroupDetails []struct {
+			LevelLearnedAt  int `json:"level_learned_at"`
+			MoveLearnMethod struct {
+				Name string `json:"name"`
+			} `json:"move_learn_method"`
+			VersionGroup struct {
+				Name string `json:"name"`
+				URL  string `json:"url"`
+			} `json:"version_group"`
+		} `json:"version_group_details"`
+	} `json:"moves"`
 }
 
+type PokeMoveDataSourceRes struct {
+	Name     string `json:"name"`
+	Power    *int   `json:"power"`
+	Accuracy *int   `json:"accuracy"`
+	PP       *int   `json:"pp"`
+	Priority int    `json:"priority"`
+	Type     struct {
+		Name string `json:"name"`
+	} `json:"type"`
+	DamageClass struct {
+		Name string `json:"name"`
+	} `json:"damage_class"`
+}
+
+const (
+	HistorySortNewest = "newest"
+	HistorySortOldest = "oldest"
+)
+
 type PokemonReqQuery struct {
-	StartDate string `json:"start_date"`
-	EndDate   string `json:"end_date"`
+	StartDate    string   `json:"start_date"`
+	EndDate      string   `json:"end_date"`
+	Pokemon      string   `json:"pokemon"`
+	Participants int      `json:"participants"`
+	MinScore     *float64 `json:"min_score"`
+	Cancelled    *bool    `json:"cancelled"`
+	Sort         string   `json:"sort"`
+	Cursor       uint     `json:"cursor"`
+	Limit        int      `json:"limit"`
+}
+
+type CursorPage struct {
+	NextCursor string `json:"next_cursor"`
+	Total      int64  `json:"total"`
 }
 
 type PokemonCreateReqBody struct {
-	Pokemon []string `json:"pokemon"`
+	Pokemon        []string   `json:"pokemon"`
+	Specs          []StatSpec `json:"specs"`
+	Mode           string     `json:"mode"`
+	Seed           *int64     `json:"seed"`
+	Scoring        string     `json:"scoring"`
+	CPFormula      string     `json:"cp_formula"`
+	TrainerID      *uint      `json:"trainer_id"`
+	TrainerPokemon []string   `json:"trainer_pokemon"`
+}
+
+type FightResult struct {
+	FightHistoryID uint       `json:"fight_history_id"`
+	TrainerID      *uint      `json:"trainer_id"`
+	Mode           string     `json:"mode"`
+	Seed           int64      `json:"seed"`
+	EngineVersion  string     `json:"engine_version"`
+	ScoringRule    string     `json:"scoring_rule"`
+	CPFormula      string     `json:"cp_formula"`
+	Standings      []Standing `json:"standings"`
+	Pokemon        []Pokemon  `json:"pokemon"`
+}
+
+type Standing struct {
+	Rank    int     `json:"rank"`
+	Pokemon string  `json:"pokemon"`
+	Score   float64 `json:"score"`
+}
+
+type RescoreReqBody struct {
+	Scoring string `json:"scoring"`
+}
+
+type FightConfig struct {
+	ScoringRule     string
+	ScoringTable    []int
+	CPFormula       string
+	CPWeights       map[string]float64
+	TieBreakers     []string
+	MinParticipants int
+	MaxParticipants int
+}
+
+type ReplayResult struct {
+	FightHistoryID       uint     `json:"fight_history_id"`
+	Mode                 string   `json:"mode"`
+	Seed                 int64    `json:"seed"`
+	EngineVersion        string   `json:"engine_version"`
+	CurrentEngineVersion string   `json:"current_engine_version"`
+	Match                bool     `json:"match"`
+	Stored               []string `json:"stored"`
+	Replayed             []string `json:"replayed"`
 }
 
 type PokemonCancelReqBody struct {
 	FightHistoryID int    `json:"fight_history_id"`
 	Pokemon        string `json:"pokemon"`
+	Actor          string `json:"-"`
+	Reason         string `json:"reason"`
+}
+
+type CancelRevertReqBody struct {
+	Actor  string `json:"-"`
+	Reason string `json:"reason"`
 }
 
 type Leaderboard struct {
-	Pokemon    string `json:"pokemon"`
-	TotalScore int    `json:"total_score"`
+	Rank         int     `json:"rank"`
+	Pokemon      string  `json:"pokemon"`
+	TotalScore   float64 `json:"total_score"`
+	Fights       int     `json:"fights"`
+	Wins         int     `json:"wins"`
+	AverageScore float64 `json:"average_score"`
+	WinRate      float64 `json:"win_rate"`
+}
+
+type LeaderboardReqQuery struct {
+	SeasonID  *uint  `json:"season_id"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Limit     int    `json:"limit"`
+	Offset    int    `json:"offset"`
 }
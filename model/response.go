@@ -8,6 +8,21 @@ type ResponseList struct {
 	Error     any `json:"errors"`
 }
 
+type ResponseCursor struct {
+	Data       any    `json:"data"`
+	NextCursor string `json:"next_cursor"`
+	DataTotal  int64  `json:"data_total"`
+	Error      any    `json:"errors"`
+}
+
+type ResponseOffset struct {
+	Offset    int `json:"offset"`
+	Limit     int `json:"limit"`
+	Data      any `json:"data"`
+	DataTotal int `json:"data_total"`
+	Error     any `json:"errors"`
+}
+
 type Response struct {
 	Data  any `json:"data"`
 	Error any `json:"errors"`
@@ -0,0 +1,12 @@
+package model
+
+import (
+	"time"
+)
+
+type SeasonCreateReqBody struct {
+	Name     string     `json:"name"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Scoring  string     `json:"scoring"`
+}
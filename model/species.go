@@ -0,0 +1,151 @@
+package model
+
+type Species struct {
+	ID               int      `json:"id"`
+	Name             string   `json:"name"`
+	Genus            string   `json:"genus"`
+	FlavorText       string   `json:"flavor_text"`
+	Generation       string   `json:"generation"`
+	Habitat          string   `json:"habitat,omitempty"`
+	GrowthRate       string   `json:"growth_rate"`
+	CaptureRate      int      `json:"capture_rate"`
+	BaseHappiness    *int     `json:"base_happiness"`
+	Genderless       bool     `json:"genderless"`
+	FemaleRatio      float64  `json:"female_ratio"`
+	IsBaby           bool     `json:"is_baby"`
+	IsLegendary      bool     `json:"is_legendary"`
+	IsMythical       bool     `json:"is_mythical"`
+	EvolvesFrom      string   `json:"evolves_from,omitempty"`
+	EvolutionChainID int      `json:"evolution_chain_id"`
+	Varieties        []string `json:"varieties"`
+}
+
+type EvolutionChain struct {
+	ID         int         `json:"id"`
+	Pokemon    string      `json:"pokemon"`
+	Species    string      `json:"species"`
+	CPFormula  string      `json:"cp_formula"`
+	Evolutions []Evolution `json:"evolutions"`
+}
+
+// Evolution is one species of an evolution chain. Stage is 1 for the first
+// species of the chain; Conditions lists the ways to evolve into it, any one
+// of which is enough.
+type Evolution struct {
+	Species     string               `json:"species"`
+	Pokemon     string               `json:"pokemon"`
+	Stage       int                  `json:"stage"`
+	IsBaby      bool                 `json:"is_baby"`
+	EvolvesFrom string               `json:"evolves_from,omitempty"`
+	Conditions  []EvolutionCondition `json:"conditions,omitempty"`
+	CombatPower float64              `json:"combat_power"`
+	CPDelta     float64              `json:"cp_delta"`
+}
+
+// EvolutionCondition is a trigger, e.g. level-up, use-item or trade, with
+// every requirement that has to hold alongside it.
+type EvolutionCondition struct {
+	Trigger               string `json:"trigger"`
+	MinLevel              int    `json:"min_level,omitempty"`
+	Item                  string `json:"item,omitempty"`
+	HeldItem              string `json:"held_item,omitempty"`
+	KnownMove             string `json:"known_move,omitempty"`
+	KnownMoveType         string `json:"known_move_type,omitempty"`
+	Location              string `json:"location,omitempty"`
+	TimeOfDay             string `json:"time_of_day,omitempty"`
+	Gender                string `json:"gender,omitempty"`
+	MinHappiness          int    `json:"min_happiness,omitempty"`
+	MinAffection          int    `json:"min_affection,omitempty"`
+	MinBeauty             int    `json:"min_beauty,omitempty"`
+	PartySpecies          string `json:"party_species,omitempty"`
+	PartyType             string `json:"party_type,omitempty"`
+	TradeSpecies          string `json:"trade_species,omitempty"`
+	RelativePhysicalStats string `json:"relative_physical_stats,omitempty"`
+	NeedsOverworldRain    bool   `json:"needs_overworld_rain,omitempty"`
+	TurnUpsideDown        bool   `json:"turn_upside_down,omitempty"`
+}
+
+type PokeSpeciesDataSourceRes struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	GenderRate    int    `json:"gender_rate"`
+	CaptureRate   int    `json:"capture_rate"`
+	BaseHappiness *int   `json:"base_happiness"`
+	IsBaby        bool   `json:"is_baby"`
+	IsLegendary   bool   `json:"is_legendary"`
+	IsMythical    bool   `json:"is_mythical"`
+	Generation    struct {
+		Name string `json:"name"`
+	} `json:"generation"`
+	Habitat *struct {
+		Name string `json:"name"`
+	} `json:"habitat"`
+	GrowthRate struct {
+		Name string `json:"name"`
+	} `json:"growth_rate"`
+	EvolvesFromSpecies *struct {
+		Name string `json:"name"`
+	} `json:"evolves_from_species"`
+	EvolutionChain *struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+	Varieties []struct {
+		IsDefault bool `json:"is_default"`
+		Pokemon   struct {
+			Name string `json:"name"`
+		} `json:"pokemon"`
+	} `json:"varieties"`
+	Genera []struct {
+		Genus    string `json:"genus"`
+		Language struct {
+			Name string `json:"name"`
+		} `json:"language"`
+	} `json:"genera"`
+	FlavorTextEntries []struct {
+		FlavorText string `json:"flavor_text"`
+		Language   struct {
+			Name string `json:"name"`
+		} `json:"language"`
+	} `json:"flavor_text_entries"`
+}
+
+type PokeEvolutionChainDataSourceRes struct {
+	ID    int                                 `json:"id"`
+	Chain PokeEvolutionChainLinkDataSourceRes `json:"chain"`
+}
+
+type PokeEvolutionChainLinkDataSourceRes struct {
+	IsBaby  bool `json:"is_baby"`
+	Species struct {
+		Name string `json:"name"`
+	} `json:"species"`
+	EvolutionDetails []PokeEvolutionDetailDataSourceRes    `json:"evolution_details"`
+	EvolvesTo        []PokeEvolutionChainLinkDataSourceRes `json:"evolves_to"`
+}
+
+// PokeEvolutionDetailDataSourceRes leaves every requirement that does not apply
+// null, or empty for time_of_day.
+type PokeEvolutionDetailDataSourceRes struct {
+	Trigger               NamedDataSourceRes  `json:"trigger"`
+	MinLevel              *int                `json:"min_level"`
+	Item                  *NamedDataSourceRes `json:"item"`
+	HeldItem              *NamedDataSourceRes `json:"held_item"`
+	KnownMove             *NamedDataSourceRes `json:"known_move"`
+	KnownMoveType         *NamedDataSourceRes `json:"known_move_type"`
+	Location              *NamedDataSourceRes `json:"location"`
+	TimeOfDay             string              `json:"time_of_day"`
+	Gender                *int                `json:"gender"`
+	MinHappiness          *int                `json:"min_happiness"`
+	MinAffection          *int                `json:"min_affection"`
+	MinBeauty             *int                `json:"min_beauty"`
+	PartySpecies          *NamedDataSourceRes `json:"party_species"`
+	PartyType             *NamedDataSourceRes `json:"party_type"`
+	TradeSpecies          *NamedDataSourceRes `json:"trade_species"`
+	RelativePhysicalStats *int                `json:"relative_physical_stats"`
+	NeedsOverworldRain    bool                `json:"needs_overworld_rain"`
+	TurnUpsideDown        bool                `json:"turn_upside_down"`
+}
+
+type NamedDataSourceRes struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,70 @@
+package model
+
+type TeamFightReqBody struct {
+	Teams      []TeamReqBody `json:"teams"`
+	Specs      []StatSpec    `json:"specs"`
+	Resolution string        `json:"resolution"`
+	BestOf     int           `json:"best_of"`
+	Seed       *int64        `json:"seed"`
+	Scoring    string        `json:"scoring"`
+	CPFormula  string        `json:"cp_formula"`
+}
+
+type TeamReqBody struct {
+	TrainerID *uint    `json:"trainer_id"`
+	Pokemon   []string `json:"pokemon"`
+}
+
+type TeamFightResult struct {
+	FightHistoryID uint         `json:"fight_history_id"`
+	Resolution     string       `json:"resolution"`
+	BestOf         int          `json:"best_of,omitempty"`
+	Seed           int64        `json:"seed"`
+	EngineVersion  string       `json:"engine_version"`
+	ScoringRule    string       `json:"scoring_rule"`
+	CPFormula      string       `json:"cp_formula"`
+	Teams          []TeamResult `json:"teams"`
+	Duels          []TeamDuel   `json:"duels,omitempty"`
+}
+
+type TeamResult struct {
+	Team      int                `json:"team"`
+	TrainerID *uint              `json:"trainer_id"`
+	Rank      int                `json:"rank"`
+	Score     float64            `json:"score"`
+	Power     float64            `json:"power"`
+	MatchWins int                `json:"match_wins"`
+	DuelWins  int                `json:"duel_wins"`
+	Members   []TeamMemberResult `json:"members"`
+}
+
+type TeamMemberResult struct {
+	Pokemon     string  `json:"pokemon"`
+	Slot        int     `json:"slot"`
+	CombatPower float64 `json:"combat_power"`
+	FinalStats  []Stat  `json:"final_stats,omitempty"`
+	Duels       int     `json:"duels"`
+	Wins        int     `json:"wins"`
+	Losses      int     `json:"losses"`
+	DamageDealt int     `json:"damage_dealt"`
+}
+
+type TeamDuel struct {
+	Match      int    `json:"match"`
+	TeamA      int    `json:"team_a"`
+	TeamB      int    `json:"team_b"`
+	PokemonA   string `json:"pokemon_a"`
+	PokemonB   string `json:"pokemon_b"`
+	Winner     string `json:"winner"`
+	WinnerTeam int    `json:"winner_team"`
+	Turns      int    `json:"turns"`
+}
+
+type TeamLeaderboard struct {
+	TrainerID  uint    `json:"trainer_id"`
+	Trainer    string  `json:"trainer"`
+	Fights     int     `json:"fights"`
+	Wins       int     `json:"wins"`
+	DuelWins   int     `json:"duel_wins"`
+	TotalScore float64 `json:"total_score"`
+}
@@ -0,0 +1,30 @@
+package model
+
+const (
+	TournamentStatusRegistration = "registration"
+	TournamentStatusRunning      = "running"
+	TournamentStatusFinished     = "finished"
+
+	TournamentSeedingCombatPower = "cp"
+	TournamentSeedingRating      = "rating"
+)
+
+type TournamentCreateReqBody struct {
+	Name      string   `json:"name"`
+	Format    string   `json:"format"`
+	Rounds    int      `json:"rounds"`
+	Seeding   string   `json:"seeding"`
+	Mode      string   `json:"mode"`
+	Seed      *int64   `json:"seed"`
+	Scoring   string   `json:"scoring"`
+	TrainerID *uint    `json:"trainer_id"`
+	Pokemon   []string `json:"pokemon"`
+}
+
+type TournamentEntrantReqBody struct {
+	Pokemon []string `json:"pokemon"`
+}
+
+type TournamentPlayReqBody struct {
+	All bool `json:"all"`
+}
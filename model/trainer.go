@@ -0,0 +1,12 @@
+package model
+
+type TrainerCreateReqBody struct {
+	Name string `json:"name"`
+}
+
+type TrainerLeaderboard struct {
+	TrainerID  uint    `json:"trainer_id"`
+	Trainer    string  `json:"trainer"`
+	Fights     int     `json:"fights"`
+	TotalScore float64 `json:"total_score"`
+}
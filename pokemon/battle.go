@@ -0,0 +1,230 @@
+package pokemon
+
+import (
+	"math"
+	"math/rand"
+	"pokeapi/model"
+)
+
+const (
+	FightModeBattle = "battle"
+
+	BattleLevel        = 50
+	battleMovePower    = 60
+	battleMoveAccuracy = 95
+	criticalHitChance  = 24
+	maxBattleTurns     = 200
+)
+
+type battler struct {
+	pokemon        model.Pokemon
+	level          int
+	hp             int
+	maxHP          int
+	attack         int
+	defense        int
+	specialAttack  int
+	specialDefense int
+	speed          int
+	moves          []model.Move
+	damageDealt    int
+}
+
+func newBattler(pokemon model.Pokemon) *battler {
+	if pokemon.Spec == nil {
+		pokemon, _ = Pokemon{}.ApplyStatSpec(pokemon, model.StatSpec{}, MeanCP{})
+	}
+
+	b := &battler{pokemon: pokemon, level: pokemon.Spec.Level}
+	for _, m := range pokemon.Moves {
+		if IsDamagingMove(m) {
+			b.moves = append(b.moves, m)
+		}
+	}
+	for _, s := range pokemon.FinalStats {
+		switch s.Name {
+		case StatHP:
+			b.maxHP = s.Value
+		case StatAttack:
+			b.attack = s.Value
+		case StatDefense:
+			b.defense = s.Value
+		case StatSpecialAttack:
+			b.specialAttack = s.Value
+		case StatSpecialDefense:
+			b.specialDefense = s.Value
+		case StatSpeed:
+			b.speed = s.Value
+		}
+	}
+	if b.maxHP == 0 {
+		b.maxHP = b.level + 10
+	}
+	b.hp = b.maxHP
+	return b
+}
+
+// Battle runs a one-on-one duel between Pokémon with their final stats, at
+// BattleLevel unless their spec says otherwise. Both Pokémon attack each turn in
+// speed order with the move of their move set that is expected to do the most
+// damage, or with a fixed-power move of their best type when they know no
+// damaging move; the first to reach 0 HP loses, and after maxBattleTurns the
+// larger share of HP left wins. Speed ties, accuracy, critical hits and damage
+// rolls all draw from seed, so the same seed always replays the same battle.
+func (p Pokemon) Battle(a, b model.Pokemon, seed int64) model.BattleResult {
+	rng := rand.New(rand.NewSource(seed))
+	result := model.BattleResult{
+		Seed:          seed,
+		EngineVersion: EngineVersion,
+	}
+
+	winner, loser, turns, log := duel(rng, newBattler(a), newBattler(b))
+	result.Turns = turns
+	result.Log = log
+	result.Winner = winner.pokemon.Name
+	result.Loser = loser.pokemon.Name
+	result.Combatants = []model.BattleCombatant{winner.combatant(), loser.combatant()}
+
+	return result
+}
+
+// duel fights a and b from the HP they have left until one faints or the
+// turn limit is reached.
+func duel(rng *rand.Rand, a, b *battler) (*battler, *battler, int, []model.BattleTurn) {
+	first, second := a, b
+	if second.speed > first.speed || (second.speed == first.speed && rng.Intn(2) == 1) {
+		first, second = second, first
+	}
+
+	var turns int
+	var log []model.BattleTurn
+	for turn := 1; turn <= maxBattleTurns && first.hp > 0 && second.hp > 0; turn++ {
+		turns = turn
+		for _, attacker := range []*battler{first, second} {
+			defender := second
+			if attacker == second {
+				defender = first
+			}
+
+			t := battleDamage(rng, attacker, defender)
+			t.Turn = turn
+			attacker.damageDealt += t.Damage
+			defender.hp = int(math.Max(0, float64(defender.hp-t.Damage)))
+			t.DefenderHP = defender.hp
+			log = append(log, t)
+			if defender.hp == 0 {
+				break
+			}
+		}
+	}
+
+	winner, loser := first, second
+	if first.hp*second.maxHP < second.hp*first.maxHP || first.hp == 0 {
+		winner, loser = second, first
+	}
+	return winner, loser, turns, log
+}
+
+func battleDamage(rng *rand.Rand, attacker, defender *battler) model.BattleTurn {
+	move := attacker.chooseMove(defender)
+	log := model.BattleTurn{
+		Attacker: attacker.pokemon.Name,
+		Defender: defender.pokemon.Name,
+		Move:     move.Name,
+	}
+
+	if move.Accuracy > 0 && rng.Intn(100) >= move.Accuracy {
+		log.Missed = true
+		return log
+	}
+
+	attack, defense := attacker.stats(defender, move)
+	log.Effectiveness = TypeEffectiveness(move.Type, defender.pokemon.Types)
+	if log.Effectiveness == 0 {
+		return log
+	}
+
+	modifier := log.Effectiveness * STAB(move.Type, attacker.pokemon.Types) * float64(85+rng.Intn(16)) / 100
+	if rng.Intn(criticalHitChance) == 0 {
+		log.Critical = true
+		modifier *= 1.5
+	}
+
+	base := (2*attacker.level/5+2)*move.Power*attack/defense/50 + 2
+	log.Damage = int(math.Floor(float64(base) * modifier))
+	if log.Damage < 1 {
+		log.Damage = 1
+	}
+	return log
+}
+
+// chooseMove picks the move with the highest expected damage against the
+// defender, the first one on a tie.
+func (b *battler) chooseMove(defender *battler) model.Move {
+	if len(b.moves) == 0 {
+		return b.fallbackMove(defender)
+	}
+
+	var best model.Move
+	bestDamage := -1.0
+	for _, m := range b.moves {
+		attack, defense := b.stats(defender, m)
+		accuracy := 1.0
+		if m.Accuracy > 0 {
+			accuracy = float64(m.Accuracy) / 100
+		}
+		damage := float64(m.Power*attack) / float64(defense) * accuracy *
+			STAB(m.Type, b.pokemon.Types) * TypeEffectiveness(m.Type, defender.pokemon.Types)
+		if damage > bestDamage {
+			best, bestDamage = m, damage
+		}
+	}
+	return best
+}
+
+// fallbackMove is a fixed-power move of the attacker's type that hits the
+// defender hardest, using its better attacking stat.
+func (b *battler) fallbackMove(defender *battler) model.Move {
+	move := model.Move{
+		DamageClass: DamageClassPhysical,
+		Power:       battleMovePower,
+		Accuracy:    battleMoveAccuracy,
+	}
+	if b.specialAttack > b.attack {
+		move.DamageClass = DamageClassSpecial
+	}
+	best := -1.0
+	for _, t := range b.pokemon.Types {
+		if m := TypeEffectiveness(t, defender.pokemon.Types); m > best {
+			move.Type, best = t, m
+		}
+	}
+	return move
+}
+
+// stats returns the attacking and defending stat the move's damage class uses.
+func (b *battler) stats(defender *battler, move model.Move) (int, int) {
+	attack, defense := b.attack, defender.defense
+	if move.DamageClass == DamageClassSpecial {
+		attack, defense = b.specialAttack, defender.specialDefense
+	}
+	if defense < 1 {
+		defense = 1
+	}
+	return attack, defense
+}
+
+func (b *battler) combatant() model.BattleCombatant {
+	return model.BattleCombatant{
+		Name:        b.pokemon.Name,
+		Types:       b.pokemon.Types,
+		Level:       b.level,
+		MaxHP:       b.maxHP,
+		RemainingHP: b.hp,
+		Attack:      b.attack,
+		Defense:     b.defense,
+		SpAttack:    b.specialAttack,
+		SpDefense:   b.specialDefense,
+		Speed:       b.speed,
+	}
+}
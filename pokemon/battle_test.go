@@ -0,0 +1,91 @@
+package pokemon_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func battleStats(hp, attack, defense, specialAttack, specialDefense, speed int) []model.Stat {
+	return []model.Stat{
+		{Name: "hp", Value: hp},
+		{Name: "attack", Value: attack},
+		{Name: "defense", Value: defense},
+		{Name: "special-attack", Value: specialAttack},
+		{Name: "special-defense", Value: specialDefense},
+		{Name: "speed", Value: speed},
+	}
+}
+
+func TestBattle(t *testing.T) {
+	p := pokemon.New()
+	charmander := model.Pokemon{Name: "charmander", Types: []string{"fire"}, Stats: battleStats(39, 52, 43, 60, 50, 65)}
+	bulbasaur := model.Pokemon{Name: "bulbasaur", Types: []string{"grass", "poison"}, Stats: battleStats(45, 49, 49, 65, 65, 45)}
+
+	result := p.Battle(bulbasaur, charmander, 42)
+	assert.Equal(t, "charmander", result.Winner)
+	assert.Equal(t, "bulbasaur", result.Loser)
+	assert.Equal(t, int64(42), result.Seed)
+	assert.Equal(t, pokemon.EngineVersion, result.EngineVersion)
+	assert.Equal(t, "charmander", result.Log[0].Attacker)
+	assert.Equal(t, 0, result.Log[len(result.Log)-1].DefenderHP)
+	assert.Equal(t, "charmander", result.Log[len(result.Log)-1].Attacker)
+	assert.Equal(t, result.Log[len(result.Log)-1].Turn, result.Turns)
+	assert.Equal(t, 0, result.Combatants[1].RemainingHP)
+	assert.Equal(t, 105, result.Combatants[1].MaxHP)
+	assert.Equal(t, 70, result.Combatants[0].Speed)
+
+	hp := map[string]int{"charmander": result.Combatants[0].MaxHP, "bulbasaur": result.Combatants[1].MaxHP}
+	for _, turn := range result.Log {
+		hp[turn.Defender] -= turn.Damage
+		if hp[turn.Defender] < 0 {
+			hp[turn.Defender] = 0
+		}
+		assert.Equal(t, hp[turn.Defender], turn.DefenderHP)
+		if turn.Missed {
+			assert.Equal(t, 0, turn.Damage)
+		} else if turn.Attacker == "charmander" {
+			assert.Equal(t, 2.0, turn.Effectiveness)
+		}
+	}
+}
+
+func TestBattleSeed(t *testing.T) {
+	p := pokemon.New()
+	pikachu := model.Pokemon{Name: "pikachu", Types: []string{"electric"}, Stats: battleStats(35, 55, 40, 50, 50, 90)}
+	raichu := model.Pokemon{Name: "raichu", Types: []string{"electric"}, Stats: battleStats(35, 55, 40, 50, 50, 90)}
+
+	first := p.Battle(pikachu, raichu, 7)
+	assert.Equal(t, first, p.Battle(pikachu, raichu, 7))
+
+	starters := map[string]bool{}
+	rolls := map[int]bool{}
+	for seed := int64(0); seed < 50; seed++ {
+		result := p.Battle(pikachu, raichu, seed)
+		starters[result.Log[0].Attacker] = true
+		rolls[result.Log[0].Damage] = true
+	}
+	assert.Len(t, starters, 2)
+	assert.Greater(t, len(rolls), 2)
+}
+
+func TestBattleImmune(t *testing.T) {
+	p := pokemon.New()
+	snorlax := model.Pokemon{Name: "snorlax", Types: []string{"normal"}, Stats: battleStats(160, 110, 65, 65, 110, 30)}
+	gengar := model.Pokemon{Name: "gengar", Types: []string{"ghost", "poison"}, Stats: battleStats(60, 65, 60, 130, 75, 110)}
+
+	result := p.Battle(snorlax, gengar, 1)
+	assert.Equal(t, "gengar", result.Winner)
+	for _, turn := range result.Log {
+		if turn.Attacker == "snorlax" {
+			assert.Equal(t, 0, turn.Damage)
+		}
+	}
+
+	ghost := model.Pokemon{Name: "ghost", Types: []string{"ghost"}, Stats: battleStats(50, 50, 50, 50, 50, 50)}
+	normal := model.Pokemon{Name: "normal", Types: []string{"normal"}, Stats: battleStats(50, 50, 50, 50, 50, 60)}
+	result = p.Battle(ghost, normal, 1)
+	assert.Equal(t, 200, result.Turns)
+	assert.Equal(t, "normal", result.Winner)
+}
@@ -0,0 +1,213 @@
+package pokemon
+
+import (
+	"fmt"
+	"math"
+	"pokeapi/model"
+	"strconv"
+	"strings"
+)
+
+const (
+	CPFormulaMean      = "mean"
+	CPFormulaWeighted  = "weighted"
+	CPFormulaPokemonGo = "pokemon-go"
+	CPFormulaTotal     = "total"
+
+	// goCPMultiplier is the Pokémon GO CP multiplier at level 40.
+	goCPMultiplier = 0.7903
+	goMinCP        = 10
+)
+
+// DefaultCPWeights favours the attacking stats; stats without a weight count
+// once.
+var DefaultCPWeights = map[string]float64{
+	StatAttack:        1.5,
+	StatSpecialAttack: 1.5,
+}
+
+// CPFormula turns a Pokémon's stats into its combat power. Name is stored on
+// each fight and can be parsed back with NewCPFormula to replay it.
+type CPFormula interface {
+	Name() string
+	CombatPower(pokemon model.Pokemon) float64
+}
+
+// cpStats returns the stats a formula works from: the final stats once a
+// spec has been applied, the base stats otherwise.
+func cpStats(pokemon model.Pokemon) []model.Stat {
+	if len(pokemon.FinalStats) > 0 {
+		return pokemon.FinalStats
+	}
+	return pokemon.Stats
+}
+
+// MeanCP is the mean of the stats, rounded to 2 decimals.
+type MeanCP struct{}
+
+func (f MeanCP) Name() string {
+	return CPFormulaMean
+}
+
+func (f MeanCP) CombatPower(pokemon model.Pokemon) float64 {
+	stats := cpStats(pokemon)
+	if len(stats) == 0 {
+		return 0
+	}
+	var cp float64
+	for _, s := range stats {
+		cp += float64(s.Value)
+	}
+	return math.Round(cp/float64(len(stats))*100) / 100
+}
+
+// WeightedCP is the mean of the stats weighted by stat name, rounded to 2
+// decimals. Stats missing from Weights have a weight of 1.
+type WeightedCP struct {
+	Weights map[string]float64
+	Label   string
+}
+
+func (f WeightedCP) Name() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return CPFormulaWeighted + ":" + FormatCPWeights(f.Weights)
+}
+
+func (f WeightedCP) CombatPower(pokemon model.Pokemon) float64 {
+	var cp, total float64
+	for _, s := range cpStats(pokemon) {
+		weight, ok := f.Weights[s.Name]
+		if !ok {
+			weight = 1
+		}
+		cp += weight * float64(s.Value)
+		total += weight
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Round(cp/total*100) / 100
+}
+
+// TotalCP is the sum of the stats, the base stat total for a Pokémon without
+// a spec.
+type TotalCP struct{}
+
+func (f TotalCP) Name() string {
+	return CPFormulaTotal
+}
+
+func (f TotalCP) CombatPower(pokemon model.Pokemon) float64 {
+	var cp int
+	for _, s := range cpStats(pokemon) {
+		cp += s.Value
+	}
+	return float64(cp)
+}
+
+// PokemonGoCP is the Pokémon GO formula at level 40. It converts the base
+// stats with GoStats and adds the spec's IVs halved to GO's 0-15 range:
+//
+//	CP = ⌊(attack + IV) × √(defense + IV) × √(stamina + IV) × 0.7903² / 10⌋
+//
+// with a minimum of 10.
+type PokemonGoCP struct{}
+
+func (f PokemonGoCP) Name() string {
+	return CPFormulaPokemonGo
+}
+
+func (f PokemonGoCP) CombatPower(pokemon model.Pokemon) float64 {
+	attack, defense, stamina := GoStats(pokemon.Stats)
+	if pokemon.Spec != nil {
+		attack += pokemon.Spec.IVs[StatAttack] / 2
+		defense += pokemon.Spec.IVs[StatDefense] / 2
+		stamina += pokemon.Spec.IVs[StatHP] / 2
+	}
+
+	cp := math.Floor(float64(attack) * math.Sqrt(float64(defense)) * math.Sqrt(float64(stamina)) * goCPMultiplier * goCPMultiplier / 10)
+	return math.Max(cp, goMinCP)
+}
+
+// GoStats converts main-series base stats to Pokémon GO's attack, defense
+// and stamina:
+//
+//	attack  = round(round(2 × (7/8 × higher + 1/8 × lower)) × speed modifier)
+//	defense = round(round(2 × (5/8 × higher + 3/8 × lower)) × speed modifier)
+//	stamina = ⌊1.75 × HP + 50⌋
+//
+// where higher and lower are the physical and special stat in that order and
+// the speed modifier is 1 + (speed − 75) / 500.
+func GoStats(stats []model.Stat) (attack int, defense int, stamina int) {
+	base := make(map[string]float64, len(stats))
+	for _, s := range stats {
+		base[s.Name] = float64(s.Value)
+	}
+	speedMod := 1 + (base[StatSpeed]-75)/500
+
+	scaled := func(a float64, b float64, higherWeight float64) int {
+		higher, lower := math.Max(a, b), math.Min(a, b)
+		return int(math.Round(math.Round(2*(higherWeight*higher+(1-higherWeight)*lower)) * speedMod))
+	}
+	attack = scaled(base[StatAttack], base[StatSpecialAttack], 7.0/8)
+	defense = scaled(base[StatDefense], base[StatSpecialDefense], 5.0/8)
+	stamina = int(math.Floor(1.75*base[StatHP] + 50))
+	return attack, defense, stamina
+}
+
+// NewCPFormula resolves a formula by name; an empty name is the mean.
+// "weighted" uses customWeights when given and DefaultCPWeights otherwise,
+// while "weighted:attack=2,speed=1.5" carries its own weights, which is how
+// custom weights are stored.
+func NewCPFormula(name string, customWeights map[string]float64) (CPFormula, error) {
+	switch {
+	case name == "" || name == CPFormulaMean:
+		return MeanCP{}, nil
+	case name == CPFormulaTotal:
+		return TotalCP{}, nil
+	case name == CPFormulaPokemonGo:
+		return PokemonGoCP{}, nil
+	case name == CPFormulaWeighted:
+		if len(customWeights) > 0 {
+			return WeightedCP{Weights: customWeights}, nil
+		}
+		return WeightedCP{Weights: DefaultCPWeights, Label: CPFormulaWeighted}, nil
+	case strings.HasPrefix(name, CPFormulaWeighted+":"):
+		weights, err := ParseCPWeights(strings.TrimPrefix(name, CPFormulaWeighted+":"))
+		if err != nil {
+			return nil, err
+		}
+		return WeightedCP{Weights: weights}, nil
+	default:
+		return nil, fmt.Errorf("unknown cp formula %q", name)
+	}
+}
+
+func ParseCPWeights(weights string) (map[string]float64, error) {
+	parsed := make(map[string]float64)
+	for _, w := range strings.Split(weights, ",") {
+		stat, value, ok := strings.Cut(strings.TrimSpace(w), "=")
+		if !ok || !isStatName(stat) {
+			return nil, fmt.Errorf("invalid cp weights %q", weights)
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight < 0 || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid cp weights %q", weights)
+		}
+		parsed[stat] = weight
+	}
+	return parsed, nil
+}
+
+// FormatCPWeights lists the weights in StatNames order.
+func FormatCPWeights(weights map[string]float64) string {
+	var formatted []string
+	for _, stat := range StatNames {
+		if weight, ok := weights[stat]; ok {
+			formatted = append(formatted, stat+"="+strconv.FormatFloat(weight, 'f', -1, 64))
+		}
+	}
+	return strings.Join(formatted, ",")
+}
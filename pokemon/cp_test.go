@@ -0,0 +1,52 @@
+package pokemon_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func TestCPFormulas(t *testing.T) {
+	pikachu := model.Pokemon{Name: "pikachu", Stats: battleStats(35, 55, 40, 50, 50, 90)}
+	perfect := &model.StatSpec{IVs: map[string]int{"hp": 31, "attack": 31, "defense": 31}}
+	testTable := []struct {
+		formula      string
+		pokemon      model.Pokemon
+		expectedCP   float64
+		expectedName string
+	}{
+		{formula: "", pokemon: pikachu, expectedCP: 53.33, expectedName: "mean"},
+		{formula: "total", pokemon: pikachu, expectedCP: 320, expectedName: "total"},
+		{formula: "weighted", pokemon: pikachu, expectedCP: 53.21, expectedName: "weighted"},
+		{formula: "weighted:attack=2,speed=0", pokemon: pikachu, expectedCP: 47.5, expectedName: "weighted:attack=2,speed=0"},
+		{formula: "pokemon-go", pokemon: pikachu, expectedCP: 722, expectedName: "pokemon-go"},
+		// the level 40 CP of a perfect Pikachu in Pokémon GO
+		{formula: "pokemon-go", pokemon: model.Pokemon{Name: "pikachu", Stats: pikachu.Stats, Spec: perfect}, expectedCP: 938, expectedName: "pokemon-go"},
+		// final stats take over from base stats once a spec is applied
+		{formula: "mean", pokemon: model.Pokemon{Name: "pikachu", Stats: pikachu.Stats, FinalStats: battleStats(95, 60, 45, 55, 55, 95)}, expectedCP: 67.5, expectedName: "mean"},
+	}
+
+	for _, test := range testTable {
+		formula, err := pokemon.NewCPFormula(test.formula, nil)
+		assert.NoError(t, err, test.formula)
+		assert.Equal(t, test.expectedName, formula.Name())
+		assert.Equal(t, test.expectedCP, formula.CombatPower(test.pokemon), test.formula)
+	}
+}
+
+func TestNewCPFormula(t *testing.T) {
+	formula, err := pokemon.NewCPFormula("weighted", map[string]float64{"speed": 2, "hp": 0.5})
+	assert.NoError(t, err)
+	assert.Equal(t, "weighted:hp=0.5,speed=2", formula.Name())
+
+	for _, name := range []string{"median", "weighted:", "weighted:luck=2", "weighted:attack=-1", "weighted:attack"} {
+		_, err := pokemon.NewCPFormula(name, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestGoStats(t *testing.T) {
+	attack, defense, stamina := pokemon.GoStats(battleStats(108, 130, 95, 80, 85, 102))
+	assert.Equal(t, []int{261, 193, 239}, []int{attack, defense, stamina})
+}
@@ -0,0 +1,166 @@
+package pokemon
+
+import (
+	"math"
+	"pokeapi/model"
+	"strconv"
+	"strings"
+)
+
+const speciesLanguage = "en"
+
+// ResourceID reads the id at the end of a PokeAPI resource URL such as
+// https://pokeapi.co/api/v2/evolution-chain/10/.
+func ResourceID(url string) (int, bool) {
+	url = strings.TrimSuffix(url, "/")
+	id, err := strconv.Atoi(url[strings.LastIndex(url, "/")+1:])
+	if err != nil || id < 1 {
+		return 0, false
+	}
+	return id, true
+}
+
+// SpeciesDataSourceToSpecies keeps the English genus and the last English
+// flavor text, with line breaks and form feeds turned into spaces. The
+// default variety is listed first.
+func (p Pokemon) SpeciesDataSourceToSpecies(speciesDataSource model.PokeSpeciesDataSourceRes) model.Species {
+	species := model.Species{
+		ID:            speciesDataSource.ID,
+		Name:          speciesDataSource.Name,
+		Generation:    speciesDataSource.Generation.Name,
+		GrowthRate:    speciesDataSource.GrowthRate.Name,
+		CaptureRate:   speciesDataSource.CaptureRate,
+		BaseHappiness: speciesDataSource.BaseHappiness,
+		Genderless:    speciesDataSource.GenderRate < 0,
+		IsBaby:        speciesDataSource.IsBaby,
+		IsLegendary:   speciesDataSource.IsLegendary,
+		IsMythical:    speciesDataSource.IsMythical,
+		Varieties:     []string{},
+	}
+	if !species.Genderless {
+		// gender_rate is the chance of being female in eighths
+		species.FemaleRatio = float64(speciesDataSource.GenderRate) / 8
+	}
+	if speciesDataSource.Habitat != nil {
+		species.Habitat = speciesDataSource.Habitat.Name
+	}
+	if speciesDataSource.EvolvesFromSpecies != nil {
+		species.EvolvesFrom = speciesDataSource.EvolvesFromSpecies.Name
+	}
+	if speciesDataSource.EvolutionChain != nil {
+		species.EvolutionChainID, _ = ResourceID(speciesDataSource.EvolutionChain.URL)
+	}
+	for _, g := range speciesDataSource.Genera {
+		if g.Language.Name == speciesLanguage {
+			species.Genus = g.Genus
+		}
+	}
+	for _, f := range speciesDataSource.FlavorTextEntries {
+		if f.Language.Name == speciesLanguage {
+			species.FlavorText = strings.Join(strings.Fields(f.FlavorText), " ")
+		}
+	}
+	for _, v := range speciesDataSource.Varieties {
+		if v.IsDefault {
+			species.Varieties = append([]string{v.Pokemon.Name}, species.Varieties...)
+		} else {
+			species.Varieties = append(species.Varieties, v.Pokemon.Name)
+		}
+	}
+
+	return species
+}
+
+// EvolutionChainDataSourceToEvolutions flattens an evolution chain depth
+// first, so every species comes after the one it evolves from. Pokemon is
+// left to the caller since the chain only names species.
+func (p Pokemon) EvolutionChainDataSourceToEvolutions(chainDataSource model.PokeEvolutionChainDataSourceRes) []model.Evolution {
+	var evolutions []model.Evolution
+	var flatten func(link model.PokeEvolutionChainLinkDataSourceRes, stage int, evolvesFrom string)
+	flatten = func(link model.PokeEvolutionChainLinkDataSourceRes, stage int, evolvesFrom string) {
+		evolution := model.Evolution{
+			Species:     link.Species.Name,
+			Stage:       stage,
+			IsBaby:      link.IsBaby,
+			EvolvesFrom: evolvesFrom,
+		}
+		for _, d := range link.EvolutionDetails {
+			evolution.Conditions = append(evolution.Conditions, evolutionCondition(d))
+		}
+		evolutions = append(evolutions, evolution)
+
+		for _, next := range link.EvolvesTo {
+			flatten(next, stage+1, link.Species.Name)
+		}
+	}
+	flatten(chainDataSource.Chain, 1, "")
+
+	return evolutions
+}
+
+func evolutionCondition(detail model.PokeEvolutionDetailDataSourceRes) model.EvolutionCondition {
+	name := func(resource *model.NamedDataSourceRes) string {
+		if resource == nil {
+			return ""
+		}
+		return resource.Name
+	}
+	value := func(v *int) int {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+
+	condition := model.EvolutionCondition{
+		Trigger:            detail.Trigger.Name,
+		MinLevel:           value(detail.MinLevel),
+		Item:               name(detail.Item),
+		HeldItem:           name(detail.HeldItem),
+		KnownMove:          name(detail.KnownMove),
+		KnownMoveType:      name(detail.KnownMoveType),
+		Location:           name(detail.Location),
+		TimeOfDay:          detail.TimeOfDay,
+		MinHappiness:       value(detail.MinHappiness),
+		MinAffection:       value(detail.MinAffection),
+		MinBeauty:          value(detail.MinBeauty),
+		PartySpecies:       name(detail.PartySpecies),
+		PartyType:          name(detail.PartyType),
+		TradeSpecies:       name(detail.TradeSpecies),
+		NeedsOverworldRain: detail.NeedsOverworldRain,
+		TurnUpsideDown:     detail.TurnUpsideDown,
+	}
+	// PokeAPI numbers genders 1 for female and 2 for male
+	switch value(detail.Gender) {
+	case 1:
+		condition.Gender = "female"
+	case 2:
+		condition.Gender = "male"
+	}
+	if detail.RelativePhysicalStats != nil {
+		switch *detail.RelativePhysicalStats {
+		case 1:
+			condition.RelativePhysicalStats = "attack > defense"
+		case 0:
+			condition.RelativePhysicalStats = "attack = defense"
+		case -1:
+			condition.RelativePhysicalStats = "attack < defense"
+		}
+	}
+	return condition
+}
+
+// EvolutionCPDeltas sets every evolution's CPDelta to the combat power it
+// gains over the species it evolves from; the first stage gains nothing.
+func EvolutionCPDeltas(evolutions []model.Evolution) {
+	cp := make(map[string]float64, len(evolutions))
+	for _, e := range evolutions {
+		cp[e.Species] = e.CombatPower
+	}
+	for i, e := range evolutions {
+		evolutions[i].CPDelta = 0
+		if from, ok := cp[e.EvolvesFrom]; ok && e.EvolvesFrom != "" {
+			evolutions[i].CPDelta = math.Round((e.CombatPower-from)*100) / 100
+		}
+	}
+}
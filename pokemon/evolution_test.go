@@ -0,0 +1,114 @@
+package pokemon_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+const pikachuSpecies = `{
+	"id": 25,
+	"name": "pikachu",
+	"gender_rate": 4,
+	"capture_rate": 190,
+	"base_happiness": 50,
+	"generation": {"name": "generation-i"},
+	"habitat": {"name": "forest"},
+	"growth_rate": {"name": "medium"},
+	"evolves_from_species": {"name": "pichu"},
+	"evolution_chain": {"url": "https://pokeapi.co/api/v2/evolution-chain/10/"},
+	"varieties": [
+		{"is_default": false, "pokemon": {"name": "pikachu-rock-star"}},
+		{"is_default": true, "pokemon": {"name": "pikachu"}}
+	],
+	"genera": [
+		{"genus": "ねずみポケモン", "language": {"name": "ja"}},
+		{"genus": "Mouse Pokémon", "language": {"name": "en"}}
+	],
+	"flavor_text_entries": [
+		{"flavor_text": "When several of\nthese POKéMON\ngather, their\felectricity could\nbuild and cause\nlightning storms.", "language": {"name": "en"}},
+		{"flavor_text": "Lorsque plusieurs de ces Pokémon se réunissent, leur énergie peut causer des orages.", "language": {"name": "fr"}}
+	]
+}`
+
+func TestSpeciesDataSourceToSpecies(t *testing.T) {
+	p := pokemon.New()
+	var speciesDataSource model.PokeSpeciesDataSourceRes
+	assert.NoError(t, json.Unmarshal([]byte(pikachuSpecies), &speciesDataSource))
+
+	species := p.SpeciesDataSourceToSpecies(speciesDataSource)
+	assert.Equal(t, "Mouse Pokémon", species.Genus)
+	assert.Equal(t, "When several of these POKéMON gather, their electricity could build and cause lightning storms.", species.FlavorText)
+	assert.Equal(t, 0.5, species.FemaleRatio)
+	assert.False(t, species.Genderless)
+	assert.Equal(t, "pichu", species.EvolvesFrom)
+	assert.Equal(t, 10, species.EvolutionChainID)
+	assert.Equal(t, []string{"pikachu", "pikachu-rock-star"}, species.Varieties)
+
+	genderless := p.SpeciesDataSourceToSpecies(model.PokeSpeciesDataSourceRes{Name: "magnemite", GenderRate: -1})
+	assert.True(t, genderless.Genderless)
+	assert.Equal(t, 0.0, genderless.FemaleRatio)
+	assert.Empty(t, genderless.EvolvesFrom)
+}
+
+const eeveeChain = `{
+	"id": 67,
+	"chain": {
+		"is_baby": false,
+		"species": {"name": "eevee"},
+		"evolution_details": [],
+		"evolves_to": [
+			{"is_baby": false, "species": {"name": "vaporeon"}, "evolves_to": [], "evolution_details": [
+				{"trigger": {"name": "use-item"}, "item": {"name": "water-stone"}, "min_level": null, "gender": null, "relative_physical_stats": null, "time_of_day": ""}
+			]},
+			{"is_baby": false, "species": {"name": "espeon"}, "evolves_to": [], "evolution_details": [
+				{"trigger": {"name": "level-up"}, "min_happiness": 160, "time_of_day": "day"},
+				{"trigger": {"name": "level-up"}, "min_affection": 2, "known_move_type": {"name": "fairy"}, "time_of_day": "day"}
+			]}
+		]
+	}
+}`
+
+func TestEvolutionChainDataSourceToEvolutions(t *testing.T) {
+	p := pokemon.New()
+	var chainDataSource model.PokeEvolutionChainDataSourceRes
+	assert.NoError(t, json.Unmarshal([]byte(eeveeChain), &chainDataSource))
+
+	evolutions := p.EvolutionChainDataSourceToEvolutions(chainDataSource)
+	assert.Equal(t, []model.Evolution{
+		{Species: "eevee", Stage: 1},
+		{Species: "vaporeon", Stage: 2, EvolvesFrom: "eevee", Conditions: []model.EvolutionCondition{
+			{Trigger: "use-item", Item: "water-stone"},
+		}},
+		{Species: "espeon", Stage: 2, EvolvesFrom: "eevee", Conditions: []model.EvolutionCondition{
+			{Trigger: "level-up", MinHappiness: 160, TimeOfDay: "day"},
+			{Trigger: "level-up", MinAffection: 2, KnownMoveType: "fairy", TimeOfDay: "day"},
+		}},
+	}, evolutions)
+}
+
+func TestEvolutionCPDeltas(t *testing.T) {
+	evolutions := []model.Evolution{
+		{Species: "pichu", CombatPower: 34.17},
+		{Species: "pikachu", EvolvesFrom: "pichu", CombatPower: 53.33},
+		{Species: "raichu", EvolvesFrom: "pikachu", CombatPower: 80.83},
+	}
+	pokemon.EvolutionCPDeltas(evolutions)
+
+	var deltas []float64
+	for _, e := range evolutions {
+		deltas = append(deltas, e.CPDelta)
+	}
+	assert.Equal(t, []float64{0, 19.16, 27.5}, deltas)
+}
+
+func TestResourceID(t *testing.T) {
+	id, ok := pokemon.ResourceID("https://pokeapi.co/api/v2/evolution-chain/10/")
+	assert.True(t, ok)
+	assert.Equal(t, 10, id)
+
+	_, ok = pokemon.ResourceID("https://pokeapi.co/api/v2/evolution-chain/")
+	assert.False(t, ok)
+}
@@ -0,0 +1,36 @@
+package pokemon
+
+import (
+	"math"
+	"pokeapi/model"
+	"sort"
+)
+
+// RankLeaderboard orders rows by total score and gives tied rows the same
+// rank (1, 1, 3), listing them by name so pages stay stable. Average score
+// and win rate are per fight actually played.
+func RankLeaderboard(rows []model.Leaderboard) []model.Leaderboard {
+	ranked := append([]model.Leaderboard(nil), rows...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].TotalScore != ranked[j].TotalScore {
+			return ranked[i].TotalScore > ranked[j].TotalScore
+		}
+		return ranked[i].Pokemon < ranked[j].Pokemon
+	})
+
+	for i := range ranked {
+		if i > 0 && ranked[i].TotalScore == ranked[i-1].TotalScore {
+			ranked[i].Rank = ranked[i-1].Rank
+		} else {
+			ranked[i].Rank = i + 1
+		}
+
+		ranked[i].AverageScore, ranked[i].WinRate = 0, 0
+		if ranked[i].Fights > 0 {
+			ranked[i].AverageScore = math.Round(ranked[i].TotalScore/float64(ranked[i].Fights)*100) / 100
+			ranked[i].WinRate = math.Round(float64(ranked[i].Wins)/float64(ranked[i].Fights)*100) / 100
+		}
+	}
+
+	return ranked
+}
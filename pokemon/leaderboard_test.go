@@ -0,0 +1,36 @@
+package pokemon_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func TestRankLeaderboard(t *testing.T) {
+	ranked := pokemon.RankLeaderboard([]model.Leaderboard{
+		{Pokemon: "squirtle", TotalScore: 8, Fights: 3, Wins: 1},
+		{Pokemon: "pikachu", TotalScore: 12, Fights: 3, Wins: 2},
+		{Pokemon: "charmander", TotalScore: 8, Fights: 2, Wins: 1},
+		{Pokemon: "bulbasaur", TotalScore: 0, Fights: 0},
+	})
+
+	testTable := []struct {
+		expectedPokemon      string
+		expectedRank         int
+		expectedAverageScore float64
+		expectedWinRate      float64
+	}{
+		{expectedPokemon: "pikachu", expectedRank: 1, expectedAverageScore: 4, expectedWinRate: 0.67},
+		{expectedPokemon: "charmander", expectedRank: 2, expectedAverageScore: 4, expectedWinRate: 0.5},
+		{expectedPokemon: "squirtle", expectedRank: 2, expectedAverageScore: 2.67, expectedWinRate: 0.33},
+		{expectedPokemon: "bulbasaur", expectedRank: 4, expectedAverageScore: 0, expectedWinRate: 0},
+	}
+
+	for i, test := range testTable {
+		assert.Equal(t, test.expectedPokemon, ranked[i].Pokemon)
+		assert.Equal(t, test.expectedRank, ranked[i].Rank, test.expectedPokemon)
+		assert.Equal(t, test.expectedAverageScore, ranked[i].AverageScore, test.expectedPokemon)
+		assert.Equal(t, test.expectedWinRate, ranked[i].WinRate, test.expectedPokemon)
+	}
+}
@@ -0,0 +1,95 @@
+package pokemon
+
+import (
+	"pokeapi/model"
+	"sort"
+)
+
+const (
+	MoveSetSize = 4
+
+	MoveLearnMethodLevelUp = "level-up"
+	DamageClassPhysical    = "physical"
+	DamageClassSpecial     = "special"
+
+	stabMultiplier = 1.5
+)
+
+// LevelUpMoves lists the moves a Pokémon has learned by levelling up to
+// level, most recently learned first. A move's level is taken from the newest
+// version group that teaches it by level-up, going by the version group id
+// PokeAPI numbers games with. Details without an id count as the oldest, and
+// version groups that cannot be told apart use their lowest level.
+func (p Pokemon) LevelUpMoves(pokeDataSource model.PokeDetailDataSourceRes, level int) []string {
+	type learned struct {
+		name  string
+		level int
+		index int
+	}
+
+	var moves []learned
+	for i, m := range pokeDataSource.Moves {
+		learnedAt, newest := -1, -1
+		for _, d := range m.VersionGroupDetails {
+			if d.MoveLearnMethod.Name != MoveLearnMethodLevelUp {
+				continue
+			}
+			versionGroup, _ := ResourceID(d.VersionGroup.URL)
+			if versionGroup > newest || (versionGroup == newest && d.LevelLearnedAt < learnedAt) {
+				learnedAt, newest = d.LevelLearnedAt, versionGroup
+			}
+		}
+		if learnedAt >= 0 && learnedAt <= level {
+			moves = append(moves, learned{name: m.Move.Name, level: learnedAt, index: i})
+		}
+	}
+
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].level != moves[j].level {
+			return moves[i].level > moves[j].level
+		}
+		return moves[i].index > moves[j].index
+	})
+
+	names := make([]string, 0, len(moves))
+	for _, m := range moves {
+		names = append(names, m.name)
+	}
+	return names
+}
+
+func (p Pokemon) MoveDataSourceToMove(moveDataSource model.PokeMoveDataSourceRes) model.Move {
+	move := model.Move{
+		Name:        moveDataSource.Name,
+		Type:        moveDataSource.Type.Name,
+		DamageClass: moveDataSource.DamageClass.Name,
+		Priority:    moveDataSource.Priority,
+	}
+	if moveDataSource.Power != nil {
+		move.Power = *moveDataSource.Power
+	}
+	if moveDataSource.Accuracy != nil {
+		move.Accuracy = *moveDataSource.Accuracy
+	}
+	if moveDataSource.PP != nil {
+		move.PP = *moveDataSource.PP
+	}
+	return move
+}
+
+// IsDamagingMove reports whether a move deals damage with a fixed power, the
+// only kind of move battles use.
+func IsDamagingMove(move model.Move) bool {
+	return move.Power > 0 && (move.DamageClass == DamageClassPhysical || move.DamageClass == DamageClassSpecial)
+}
+
+// STAB is the same-type attack bonus: 1.5 when the move shares a type with
+// the Pokémon using it.
+func STAB(moveType string, types []string) float64 {
+	for _, t := range types {
+		if t == moveType {
+			return stabMultiplier
+		}
+	}
+	return 1
+}
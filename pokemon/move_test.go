@@ -0,0 +1,127 @@
+package pokemon_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+const pikachuLearnset = `{
+	"name": "pikachu",
+	"types": [{"slot": 1, "type": {"name": "electric"}}],
+	"abilities": [
+		{"ability": {"name": "lightning-rod"}, "is_hidden": true, "slot": 3},
+		{"ability": {"name": "static"}, "is_hidden": false, "slot": 1}
+	],
+	"moves": [
+		{"move": {"name": "thunder-shock"}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "growl"}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "iron-tail"}, "version_group_details": [{"level_learned_at": 0, "move_learn_method": {"name": "machine"}}]},
+		{"move": {"name": "thunder"}, "version_group_details": [{"level_learned_at": 48, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "spark"}, "version_group_details": [
+			{"level_learned_at": 20, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "scarlet-violet", "url": "https://pokeapi.co/api/v2/version-group/25/"}},
+			{"level_learned_at": 26, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "sword-shield", "url": "https://pokeapi.co/api/v2/version-group/20/"}},
+			{"level_learned_at": 0, "move_learn_method": {"name": "machine"}, "version_group": {"name": "the-teal-mask", "url": "https://pokeapi.co/api/v2/version-group/26/"}}
+		]},
+		{"move": {"name": "thunderbolt"}, "version_group_details": [
+			{"level_learned_at": 36, "move_learn_method": {"name": "level-up"}},
+			{"level_learned_at": 30, "move_learn_method": {"name": "level-up"}}
+		]}
+	]
+}`
+
+func TestLevelUpMoves(t *testing.T) {
+	p := pokemon.New()
+	var pokeDataSource model.PokeDetailDataSourceRes
+	assert.NoError(t, json.Unmarshal([]byte(pikachuLearnset), &pokeDataSource))
+
+	assert.Equal(t, []string{"thunder", "thunderbolt", "spark", "growl", "thunder-shock"}, p.LevelUpMoves(pokeDataSource, 50))
+	assert.Equal(t, []string{"spark", "growl", "thunder-shock"}, p.LevelUpMoves(pokeDataSource, 20))
+	assert.Equal(t, []string{"thunderbolt", "spark", "growl", "thunder-shock"}, p.LevelUpMoves(pokeDataSource, 30))
+
+	// the newest version group decides, wherever PokeAPI lists it
+	spark := pokeDataSource.Moves[4].VersionGroupDetails
+	spark[0], spark[1] = spark[1], spark[0]
+	assert.Equal(t, []string{"spark", "growl", "thunder-shock"}, p.LevelUpMoves(pokeDataSource, 20))
+
+	pikachu := p.PokemonDetailDataSourceToPokemon(pokeDataSource)
+	assert.Equal(t, []model.Ability{{Name: "static"}, {Name: "lightning-rod", Hidden: true}}, pikachu.Abilities)
+}
+
+func TestMoveDataSourceToMove(t *testing.T) {
+	p := pokemon.New()
+	testTable := []struct {
+		moveJSON         string
+		expectedMove     model.Move
+		expectedDamaging bool
+	}{
+		{
+			moveJSON:         `{"name": "thunderbolt", "power": 90, "accuracy": 100, "pp": 15, "priority": 0, "type": {"name": "electric"}, "damage_class": {"name": "special"}}`,
+			expectedMove:     model.Move{Name: "thunderbolt", Type: "electric", DamageClass: "special", Power: 90, Accuracy: 100, PP: 15},
+			expectedDamaging: true,
+		},
+		{
+			moveJSON:         `{"name": "swift", "power": 60, "accuracy": null, "pp": 20, "priority": 0, "type": {"name": "normal"}, "damage_class": {"name": "special"}}`,
+			expectedMove:     model.Move{Name: "swift", Type: "normal", DamageClass: "special", Power: 60, PP: 20},
+			expectedDamaging: true,
+		},
+		{
+			moveJSON:         `{"name": "growl", "power": null, "accuracy": 100, "pp": 40, "priority": 0, "type": {"name": "normal"}, "damage_class": {"name": "status"}}`,
+			expectedMove:     model.Move{Name: "growl", Type: "normal", DamageClass: "status", Accuracy: 100, PP: 40},
+			expectedDamaging: false,
+		},
+	}
+
+	for _, test := range testTable {
+		var moveDataSource model.PokeMoveDataSourceRes
+		assert.NoError(t, json.Unmarshal([]byte(test.moveJSON), &moveDataSource))
+		move := p.MoveDataSourceToMove(moveDataSource)
+		assert.Equal(t, test.expectedMove, move)
+		assert.Equal(t, test.expectedDamaging, pokemon.IsDamagingMove(move))
+	}
+}
+
+func TestSTAB(t *testing.T) {
+	assert.Equal(t, 1.5, pokemon.STAB("grass", []string{"grass", "poison"}))
+	assert.Equal(t, 1.5, pokemon.STAB("poison", []string{"grass", "poison"}))
+	assert.Equal(t, 1.0, pokemon.STAB("normal", []string{"grass", "poison"}))
+}
+
+func TestBattleMoves(t *testing.T) {
+	p := pokemon.New()
+	moves := []model.Move{
+		{Name: "tackle", Type: "normal", DamageClass: "physical", Power: 40, Accuracy: 100},
+		{Name: "water-gun", Type: "water", DamageClass: "special", Power: 40, Accuracy: 100},
+		{Name: "bite", Type: "dark", DamageClass: "physical", Power: 60, Accuracy: 100},
+		{Name: "growl", Type: "normal", DamageClass: "status", Accuracy: 100},
+	}
+	squirtle := model.Pokemon{Name: "squirtle", Types: []string{"water"}, Moves: moves, Stats: battleStats(44, 48, 65, 50, 64, 43)}
+	testTable := []struct {
+		defender     model.Pokemon
+		expectedMove string
+	}{
+		// water-gun has STAB and the type advantage
+		{
+			defender:     model.Pokemon{Name: "charmander", Types: []string{"fire"}, Stats: battleStats(39, 52, 43, 60, 50, 65)},
+			expectedMove: "water-gun",
+		},
+		// bite is super effective and has more power
+		{
+			defender:     model.Pokemon{Name: "gastly", Types: []string{"ghost", "poison"}, Stats: battleStats(30, 35, 30, 100, 35, 80)},
+			expectedMove: "bite",
+		},
+	}
+
+	for _, test := range testTable {
+		result := p.Battle(squirtle, test.defender, 3)
+		for _, turn := range result.Log {
+			if turn.Attacker == "squirtle" {
+				assert.Equal(t, test.expectedMove, turn.Move, test.defender.Name)
+			} else {
+				assert.Empty(t, turn.Move)
+			}
+		}
+	}
+}
@@ -1,8 +1,19 @@
 package pokemon
 
 import (
+	"fmt"
 	"math"
 	"pokeapi/model"
+	"sort"
+)
+
+// EngineVersion changes whenever fight or battle resolution changes, so a replay
+// can tell a different result apart from a different engine.
+const EngineVersion = "1.3.0"
+
+const (
+	FightModeCombatPower = "cp"
+	FightModeType        = "type"
 )
 
 type Pokemon struct{}
@@ -21,30 +32,72 @@ func (p Pokemon) PokemonDataSourceToListString(pokeDataSource model.PokeDataSour
 
 func (p Pokemon) PokemonDetailDataSourceToPokemon(pokeDataSource model.PokeDetailDataSourceRes) model.Pokemon {
 	pokemon := model.Pokemon{
+		ID:   pokeDataSource.ID,
 		Name: pokeDataSource.Name,
 	}
-	var cp float64
 	for _, p := range pokeDataSource.Stats {
-		cp += float64(p.BaseStat)
 		pokemon.Stats = append(pokemon.Stats, model.Stat{
 			Name:  p.Stat.Name,
 			Value: p.BaseStat,
 		})
 	}
-	pokemon.CombatPower = math.Round(cp/float64(len(pokeDataSource.Stats))*100) / 100
+	pokemon.CombatPower = MeanCP{}.CombatPower(pokemon)
+	for _, t := range pokeDataSource.Types {
+		pokemon.Types = append(pokemon.Types, t.Type.Name)
+	}
+	abilities := append(pokeDataSource.Abilities[:0:0], pokeDataSource.Abilities...)
+	sort.SliceStable(abilities, func(i, j int) bool {
+		return abilities[i].Slot < abilities[j].Slot
+	})
+	for _, a := range abilities {
+		pokemon.Abilities = append(pokemon.Abilities, model.Ability{
+			Name:   a.Ability.Name,
+			Hidden: a.IsHidden,
+		})
+	}
 
 	return pokemon
 }
 
-func (p Pokemon) FightPokemon(pokemons []model.Pokemon) []model.Pokemon {
-	n := len(pokemons)
-	for i := 0; i < n-1; i++ {
-		for j := 0; j < n-i-1; j++ {
-			if pokemons[j].CombatPower < pokemons[j+1].CombatPower {
-				pokemons[j], pokemons[j+1] = pokemons[j+1], pokemons[j]
+// Fight ranks the Pokémon in the given mode. Ties on power are settled by
+// the tie breakers, drawing any coin flip from seed; Pokémon still level share
+// a rank.
+func (p Pokemon) Fight(mode string, pokemons []model.Pokemon, tieBreakers []string, seed int64) ([]model.Pokemon, error) {
+	switch mode {
+	case "", FightModeCombatPower:
+		return p.FightPokemon(pokemons, tieBreakers, seed), nil
+	case FightModeType:
+		return p.FightPokemonByType(pokemons, tieBreakers, seed), nil
+	default:
+		return nil, fmt.Errorf("unknown fight mode %q", mode)
+	}
+}
+
+func (p Pokemon) FightPokemon(pokemons []model.Pokemon, tieBreakers []string, seed int64) []model.Pokemon {
+	return rankPokemon(pokemons, func(pokemon model.Pokemon) float64 {
+		return pokemon.CombatPower
+	}, tieBreakers, seed)
+}
+
+// FightPokemonByType scales each contender's CP by the average type multiplier
+// of its attacks against every other contender, then ranks on that power.
+func (p Pokemon) FightPokemonByType(pokemons []model.Pokemon, tieBreakers []string, seed int64) []model.Pokemon {
+	for i := range pokemons {
+		if len(pokemons) < 2 {
+			pokemons[i].EffectivePower = pokemons[i].CombatPower
+			continue
+		}
+		var multiplier float64
+		for j := range pokemons {
+			if i != j {
+				multiplier += MatchupMultiplier(pokemons[i].Types, pokemons[j].Types)
 			}
 		}
+		multiplier /= float64(len(pokemons) - 1)
+		pokemons[i].EffectivePower = math.Round(pokemons[i].CombatPower*multiplier*100) / 100
 	}
 
-	return pokemons
+	return rankPokemon(pokemons, func(pokemon model.Pokemon) float64 {
+		return pokemon.EffectivePower
+	}, tieBreakers, seed)
 }
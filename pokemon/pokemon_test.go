@@ -182,22 +182,27 @@ func TestFightPokemon(t *testing.T) {
 					Name:        "snorlax",
 					Stats:       nil,
 					CombatPower: 150,
+					Rank:        1,
 				}, {
 					Name:        "bulbasaur",
 					Stats:       nil,
 					CombatPower: 100,
+					Rank:        2,
 				}, {
 					Name:        "ivysaur",
 					Stats:       nil,
 					CombatPower: 70,
+					Rank:        3,
 				}, {
 					Name:        "pikachu",
 					Stats:       nil,
 					CombatPower: 60,
+					Rank:        4,
 				}, {
 					Name:        "charizard",
 					Stats:       nil,
 					CombatPower: 20,
+					Rank:        5,
 				},
 			},
 		},
@@ -230,29 +235,34 @@ func TestFightPokemon(t *testing.T) {
 					Name:        "snorlax",
 					Stats:       nil,
 					CombatPower: 150,
+					Rank:        1,
 				}, {
 					Name:        "charizard",
 					Stats:       nil,
 					CombatPower: 70,
+					Rank:        2,
 				}, {
 					Name:        "pikachu",
 					Stats:       nil,
 					CombatPower: 50,
+					Rank:        3,
 				}, {
 					Name:        "bulbasaur",
 					Stats:       nil,
 					CombatPower: 30,
+					Rank:        4,
 				}, {
 					Name:        "ivysaur",
 					Stats:       nil,
 					CombatPower: 20,
+					Rank:        5,
 				},
 			},
 		},
 	}
 
 	for _, test := range testTable {
-		result := p.FightPokemon(test.pokemons)
+		result := p.FightPokemon(test.pokemons, pokemon.DefaultTieBreakers, 1)
 		assert.Equal(t, test.expectedOutcome, result)
 	}
 }
@@ -0,0 +1,164 @@
+package pokemon
+
+import (
+	"math"
+)
+
+const (
+	RatingSystemElo     = "elo"
+	RatingSystemGlicko2 = "glicko2"
+
+	DefaultEloRating = 1500.0
+	EloK             = 32.0
+
+	DefaultGlicko2Rating     = 1500.0
+	DefaultGlicko2Deviation  = 350.0
+	DefaultGlicko2Volatility = 0.06
+	Glicko2Tau               = 0.5
+
+	glicko2Scale     = 173.7178
+	glicko2Tolerance = 0.000001
+)
+
+type Glicko2Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+func NewGlicko2Rating() Glicko2Rating {
+	return Glicko2Rating{
+		Rating:     DefaultGlicko2Rating,
+		Deviation:  DefaultGlicko2Deviation,
+		Volatility: DefaultGlicko2Volatility,
+	}
+}
+
+// pairwiseScore is the result of the participant at rank a against the one at
+// rank b: 1 for a better rank, 0.5 for a shared rank, 0 otherwise.
+func pairwiseScore(a int, b int) float64 {
+	switch {
+	case a < b:
+		return 1
+	case a == b:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// EloUpdate treats a fight as a round of pairwise games between every two
+// participants, all played with the ratings from before the fight. The K
+// factor is shared out over the opponents so that a fight moves a rating by
+// at most EloK however many Pokémon take part.
+func EloUpdate(ratings []float64, ranks []int) []float64 {
+	updated := make([]float64, len(ratings))
+	copy(updated, ratings)
+	if len(ratings) < 2 {
+		return updated
+	}
+
+	k := EloK / float64(len(ratings)-1)
+	for i := range ratings {
+		var delta float64
+		for j := range ratings {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (ratings[j]-ratings[i])/400))
+			delta += pairwiseScore(ranks[i], ranks[j]) - expected
+		}
+		updated[i] = ratings[i] + k*delta
+	}
+	return updated
+}
+
+// Glicko2Update rates a fight as one Glicko-2 rating period in which every
+// participant played every other one.
+func Glicko2Update(ratings []Glicko2Rating, ranks []int) []Glicko2Rating {
+	updated := make([]Glicko2Rating, len(ratings))
+	for i := range ratings {
+		var opponents []Glicko2Rating
+		var scores []float64
+		for j := range ratings {
+			if i == j {
+				continue
+			}
+			opponents = append(opponents, ratings[j])
+			scores = append(scores, pairwiseScore(ranks[i], ranks[j]))
+		}
+		updated[i] = Glicko2Period(ratings[i], opponents, scores)
+	}
+	return updated
+}
+
+// Glicko2Period applies one rating period of Glickman's Glicko-2 algorithm to
+// player, who scored scores[i] against opponents[i].
+func Glicko2Period(player Glicko2Rating, opponents []Glicko2Rating, scores []float64) Glicko2Rating {
+	mu := (player.Rating - DefaultGlicko2Rating) / glicko2Scale
+	phi := player.Deviation / glicko2Scale
+	sigma := player.Volatility
+
+	if len(opponents) == 0 {
+		phi = math.Sqrt(phi*phi + sigma*sigma)
+		return Glicko2Rating{
+			Rating:     player.Rating,
+			Deviation:  phi * glicko2Scale,
+			Volatility: sigma,
+		}
+	}
+
+	var vInv, sum float64
+	for i, o := range opponents {
+		muJ := (o.Rating - DefaultGlicko2Rating) / glicko2Scale
+		phiJ := o.Deviation / glicko2Scale
+		g := 1 / math.Sqrt(1+3*phiJ*phiJ/(math.Pi*math.Pi))
+		e := 1 / (1 + math.Exp(-g*(mu-muJ)))
+		vInv += g * g * e * (1 - e)
+		sum += g * (scores[i] - e)
+	}
+	v := 1 / vInv
+	delta := v * sum
+
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(Glicko2Tau*Glicko2Tau)
+	}
+
+	lower := a
+	var upper float64
+	if delta*delta > phi*phi+v {
+		upper = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*Glicko2Tau) < 0 {
+			k++
+		}
+		upper = a - k*Glicko2Tau
+	}
+
+	fLower, fUpper := f(lower), f(upper)
+	for math.Abs(upper-lower) > glicko2Tolerance {
+		c := lower + (lower-upper)*fLower/(fUpper-fLower)
+		fC := f(c)
+		if fC*fUpper <= 0 {
+			lower, fLower = upper, fUpper
+		} else {
+			fLower /= 2
+		}
+		upper, fUpper = c, fC
+	}
+	sigma = math.Exp(lower / 2)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * sum
+
+	return Glicko2Rating{
+		Rating:     mu*glicko2Scale + DefaultGlicko2Rating,
+		Deviation:  phi * glicko2Scale,
+		Volatility: sigma,
+	}
+}
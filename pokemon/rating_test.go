@@ -0,0 +1,54 @@
+package pokemon_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func TestEloUpdate(t *testing.T) {
+	testTable := []struct {
+		name            string
+		ratings         []float64
+		ranks           []int
+		expectedRatings []float64
+	}{
+		{name: "even duel", ratings: []float64{1500, 1500}, ranks: []int{1, 2}, expectedRatings: []float64{1516, 1484}},
+		{name: "upset", ratings: []float64{1400, 1800}, ranks: []int{1, 2}, expectedRatings: []float64{1429.09, 1770.91}},
+		{name: "draw", ratings: []float64{1500, 1500}, ranks: []int{1, 1}, expectedRatings: []float64{1500, 1500}},
+		{name: "three way", ratings: []float64{1500, 1500, 1500}, ranks: []int{1, 2, 3}, expectedRatings: []float64{1516, 1500, 1484}},
+		{name: "single", ratings: []float64{1500}, ranks: []int{1}, expectedRatings: []float64{1500}},
+	}
+
+	for _, test := range testTable {
+		ratings := pokemon.EloUpdate(test.ratings, test.ranks)
+		for i := range ratings {
+			assert.InDelta(t, test.expectedRatings[i], ratings[i], 0.01, test.name)
+		}
+	}
+}
+
+func TestGlicko2Period(t *testing.T) {
+	// the worked example from Glickman's description of Glicko-2
+	rating := pokemon.Glicko2Period(
+		pokemon.Glicko2Rating{Rating: 1500, Deviation: 200, Volatility: 0.06},
+		[]pokemon.Glicko2Rating{
+			{Rating: 1400, Deviation: 30, Volatility: 0.06},
+			{Rating: 1550, Deviation: 100, Volatility: 0.06},
+			{Rating: 1700, Deviation: 300, Volatility: 0.06},
+		},
+		[]float64{1, 0, 0},
+	)
+
+	assert.InDelta(t, 1464.06, rating.Rating, 0.01)
+	assert.InDelta(t, 151.52, rating.Deviation, 0.01)
+	assert.InDelta(t, 0.05999, rating.Volatility, 0.00001)
+}
+
+func TestGlicko2Update(t *testing.T) {
+	ratings := pokemon.Glicko2Update([]pokemon.Glicko2Rating{pokemon.NewGlicko2Rating(), pokemon.NewGlicko2Rating()}, []int{1, 2})
+	assert.Greater(t, ratings[0].Rating, pokemon.DefaultGlicko2Rating)
+	assert.Less(t, ratings[1].Rating, pokemon.DefaultGlicko2Rating)
+	assert.InDelta(t, ratings[0].Rating-pokemon.DefaultGlicko2Rating, pokemon.DefaultGlicko2Rating-ratings[1].Rating, 0.0001)
+	assert.Less(t, ratings[0].Deviation, pokemon.DefaultGlicko2Deviation)
+}
@@ -0,0 +1,139 @@
+package pokemon
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	ScoringLinear         = "linear"
+	ScoringF1             = "f1"
+	ScoringWinnerTakesAll = "winner-takes-all"
+	ScoringCustom         = "custom"
+)
+
+var F1Points = []int{25, 18, 15, 12, 10, 8, 6, 4, 2, 1}
+
+// ScoringRule turns a placement into leaderboard points. Name is stored on each
+// fight and can be parsed back with NewScoringRule to rescore it later.
+type ScoringRule interface {
+	Name() string
+	Score(rank int, participants int) int
+}
+
+// LinearScoring gives the winner Start points (or one per participant when
+// there are more participants than that) and one point less per place.
+type LinearScoring struct {
+	Start int
+}
+
+func (s LinearScoring) Name() string {
+	return ScoringLinear
+}
+
+func (s LinearScoring) Score(rank int, participants int) int {
+	start := s.Start
+	if participants > start {
+		start = participants
+	}
+	if rank < 1 || rank > start {
+		return 0
+	}
+	return start - rank + 1
+}
+
+type TableScoring struct {
+	Label string
+	Table []int
+}
+
+func (s TableScoring) Name() string {
+	if s.Label != "" {
+		return s.Label
+	}
+	return ScoringCustom + ":" + FormatScoringTable(s.Table)
+}
+
+func (s TableScoring) Score(rank int, participants int) int {
+	if rank < 1 || rank > len(s.Table) {
+		return 0
+	}
+	return s.Table[rank-1]
+}
+
+type WinnerTakesAllScoring struct {
+	Points int
+}
+
+func (s WinnerTakesAllScoring) Name() string {
+	return ScoringWinnerTakesAll
+}
+
+func (s WinnerTakesAllScoring) Score(rank int, participants int) int {
+	if rank == 1 {
+		return s.Points
+	}
+	return 0
+}
+
+// SharedScore splits the points of the places a tie covers: each of the
+// shared participants at rank gets the mean of the points for rank up to
+// rank+shared-1, rounded to 2 decimals.
+func SharedScore(rule ScoringRule, rank int, shared int, participants int) float64 {
+	if shared < 1 {
+		shared = 1
+	}
+	var points int
+	for r := rank; r < rank+shared; r++ {
+		points += rule.Score(r, participants)
+	}
+	return math.Round(float64(points)/float64(shared)*100) / 100
+}
+
+// NewScoringRule resolves a rule by name. "custom" uses customTable, while
+// "custom:10,6,3,1" carries its own table, which is how custom rules are stored.
+func NewScoringRule(name string, customTable []int) (ScoringRule, error) {
+	switch {
+	case name == "" || name == ScoringLinear:
+		return LinearScoring{Start: 5}, nil
+	case name == ScoringF1:
+		return TableScoring{Label: ScoringF1, Table: F1Points}, nil
+	case name == ScoringWinnerTakesAll:
+		return WinnerTakesAllScoring{Points: 5}, nil
+	case name == ScoringCustom:
+		if len(customTable) == 0 {
+			return nil, fmt.Errorf("no custom scoring table configured")
+		}
+		return TableScoring{Table: customTable}, nil
+	case strings.HasPrefix(name, ScoringCustom+":"):
+		table, err := ParseScoringTable(strings.TrimPrefix(name, ScoringCustom+":"))
+		if err != nil {
+			return nil, err
+		}
+		return TableScoring{Table: table}, nil
+	default:
+		return nil, fmt.Errorf("unknown scoring rule %q", name)
+	}
+}
+
+func ParseScoringTable(table string) ([]int, error) {
+	var points []int
+	for _, p := range strings.Split(table, ",") {
+		point, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || point < 0 {
+			return nil, fmt.Errorf("invalid scoring table %q", table)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+func FormatScoringTable(table []int) string {
+	points := make([]string, len(table))
+	for i, p := range table {
+		points[i] = strconv.Itoa(p)
+	}
+	return strings.Join(points, ",")
+}
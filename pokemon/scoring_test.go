@@ -0,0 +1,62 @@
+package pokemon_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func TestScoringRules(t *testing.T) {
+	testTable := []struct {
+		rule            string
+		participants    int
+		expectedOutcome []int
+	}{
+		{rule: "", participants: 5, expectedOutcome: []int{5, 4, 3, 2, 1}},
+		{rule: pokemon.ScoringLinear, participants: 3, expectedOutcome: []int{5, 4, 3}},
+		{rule: pokemon.ScoringLinear, participants: 7, expectedOutcome: []int{7, 6, 5, 4, 3, 2, 1}},
+		{rule: pokemon.ScoringF1, participants: 11, expectedOutcome: []int{25, 18, 15, 12, 10, 8, 6, 4, 2, 1, 0}},
+		{rule: pokemon.ScoringWinnerTakesAll, participants: 3, expectedOutcome: []int{5, 0, 0}},
+		{rule: "custom:10, 6,3", participants: 4, expectedOutcome: []int{10, 6, 3, 0}},
+	}
+
+	for _, test := range testTable {
+		rule, err := pokemon.NewScoringRule(test.rule, nil)
+		assert.NoError(t, err)
+
+		var result []int
+		for rank := 1; rank <= test.participants; rank++ {
+			result = append(result, rule.Score(rank, test.participants))
+		}
+		assert.Equal(t, test.expectedOutcome, result, test.rule)
+	}
+}
+
+func TestNewScoringRule(t *testing.T) {
+	rule, err := pokemon.NewScoringRule(pokemon.ScoringCustom, []int{3, 2, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "custom:3,2,1", rule.Name())
+
+	stored, err := pokemon.NewScoringRule(rule.Name(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, rule, stored)
+
+	_, err = pokemon.NewScoringRule(pokemon.ScoringCustom, nil)
+	assert.Error(t, err)
+
+	_, err = pokemon.NewScoringRule("custom:3,-1", nil)
+	assert.Error(t, err)
+
+	_, err = pokemon.NewScoringRule("bowling", nil)
+	assert.Error(t, err)
+}
+
+func TestSharedScore(t *testing.T) {
+	linear, _ := pokemon.NewScoringRule(pokemon.ScoringLinear, nil)
+	f1, _ := pokemon.NewScoringRule(pokemon.ScoringF1, nil)
+
+	assert.Equal(t, 5.0, pokemon.SharedScore(linear, 1, 1, 5))
+	assert.Equal(t, 4.5, pokemon.SharedScore(linear, 1, 2, 5))
+	assert.Equal(t, 2.0, pokemon.SharedScore(linear, 3, 3, 5))
+	assert.Equal(t, 19.33, pokemon.SharedScore(f1, 1, 3, 10))
+}
@@ -0,0 +1,153 @@
+package pokemon
+
+import (
+	"fmt"
+	"pokeapi/model"
+)
+
+const (
+	StatHP             = "hp"
+	StatAttack         = "attack"
+	StatDefense        = "defense"
+	StatSpecialAttack  = "special-attack"
+	StatSpecialDefense = "special-defense"
+	StatSpeed          = "speed"
+
+	DefaultNature = "hardy"
+	MinLevel      = 1
+	MaxLevel      = 100
+	MaxIV         = 31
+	MaxEV         = 252
+	MaxTotalEV    = 510
+)
+
+// StatNames lists the six stats in PokeAPI order.
+var StatNames = []string{StatHP, StatAttack, StatDefense, StatSpecialAttack, StatSpecialDefense, StatSpeed}
+
+// natures maps every nature to the stat it raises by 10% and the one it
+// lowers by 10%; neutral natures change nothing.
+var natures = map[string][2]string{
+	"hardy":   {},
+	"docile":  {},
+	"serious": {},
+	"bashful": {},
+	"quirky":  {},
+	"lonely":  {StatAttack, StatDefense},
+	"brave":   {StatAttack, StatSpeed},
+	"adamant": {StatAttack, StatSpecialAttack},
+	"naughty": {StatAttack, StatSpecialDefense},
+	"bold":    {StatDefense, StatAttack},
+	"relaxed": {StatDefense, StatSpeed},
+	"impish":  {StatDefense, StatSpecialAttack},
+	"lax":     {StatDefense, StatSpecialDefense},
+	"timid":   {StatSpeed, StatAttack},
+	"hasty":   {StatSpeed, StatDefense},
+	"jolly":   {StatSpeed, StatSpecialAttack},
+	"naive":   {StatSpeed, StatSpecialDefense},
+	"modest":  {StatSpecialAttack, StatAttack},
+	"mild":    {StatSpecialAttack, StatDefense},
+	"quiet":   {StatSpecialAttack, StatSpeed},
+	"rash":    {StatSpecialAttack, StatSpecialDefense},
+	"calm":    {StatSpecialDefense, StatAttack},
+	"gentle":  {StatSpecialDefense, StatDefense},
+	"sassy":   {StatSpecialDefense, StatSpeed},
+	"careful": {StatSpecialDefense, StatSpecialAttack},
+}
+
+func IsValidNature(name string) bool {
+	_, ok := natures[name]
+	return ok
+}
+
+func isStatName(name string) bool {
+	for _, s := range StatNames {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateStatSpec fills in the defaults of a spec, level BattleLevel and a
+// neutral nature, and checks the main-series limits on level, IVs and EVs.
+func ValidateStatSpec(spec model.StatSpec) (model.StatSpec, error) {
+	if spec.Level == 0 {
+		spec.Level = BattleLevel
+	}
+	if spec.Nature == "" {
+		spec.Nature = DefaultNature
+	}
+
+	if spec.Level < MinLevel || spec.Level > MaxLevel {
+		return spec, fmt.Errorf("level must be between %d and %d", MinLevel, MaxLevel)
+	}
+	if !IsValidNature(spec.Nature) {
+		return spec, fmt.Errorf("unknown nature %q", spec.Nature)
+	}
+	for stat, iv := range spec.IVs {
+		if !isStatName(stat) {
+			return spec, fmt.Errorf("unknown stat %q in ivs", stat)
+		}
+		if iv < 0 || iv > MaxIV {
+			return spec, fmt.Errorf("ivs must be between 0 and %d", MaxIV)
+		}
+	}
+	total := 0
+	for stat, ev := range spec.EVs {
+		if !isStatName(stat) {
+			return spec, fmt.Errorf("unknown stat %q in evs", stat)
+		}
+		if ev < 0 || ev > MaxEV {
+			return spec, fmt.Errorf("evs must be between 0 and %d", MaxEV)
+		}
+		total += ev
+	}
+	if total > MaxTotalEV {
+		return spec, fmt.Errorf("evs must not add up to more than %d", MaxTotalEV)
+	}
+
+	return spec, nil
+}
+
+// CalculateStats applies the main-series stat formulas to the base stats:
+//
+//	HP    = (2 × base + IV + EV / 4) × level / 100 + level + 10
+//	other = ((2 × base + IV + EV / 4) × level / 100 + 5) × nature
+//
+// rounding down after each step. The spec is expected to be validated.
+func CalculateStats(base []model.Stat, spec model.StatSpec) []model.Stat {
+	nature := natures[spec.Nature]
+	stats := make([]model.Stat, 0, len(base))
+	for _, s := range base {
+		value := (2*s.Value + spec.IVs[s.Name] + spec.EVs[s.Name]/4) * spec.Level / 100
+		if s.Name == StatHP {
+			value += spec.Level + 10
+		} else {
+			value += 5
+			switch s.Name {
+			case nature[0]:
+				value = value * 110 / 100
+			case nature[1]:
+				value = value * 90 / 100
+			}
+		}
+		stats = append(stats, model.Stat{Name: s.Name, Value: value})
+	}
+	return stats
+}
+
+// ApplyStatSpec computes the final stats of a Pokémon from its base stats
+// and spec, and its combat power from those with formula, so level, IVs, EVs
+// and nature count in every fight mode.
+func (p Pokemon) ApplyStatSpec(pokemon model.Pokemon, spec model.StatSpec, formula CPFormula) (model.Pokemon, error) {
+	spec, err := ValidateStatSpec(spec)
+	if err != nil {
+		return model.Pokemon{}, err
+	}
+	spec.Pokemon = pokemon.Name
+
+	pokemon.Spec = &spec
+	pokemon.FinalStats = CalculateStats(pokemon.Stats, spec)
+	pokemon.CombatPower = formula.CombatPower(pokemon)
+	return pokemon, nil
+}
@@ -0,0 +1,60 @@
+package pokemon_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func TestCalculateStats(t *testing.T) {
+	// the worked example from the main-series games: an adamant level 78
+	// Garchomp
+	garchomp := battleStats(108, 130, 95, 80, 85, 102)
+	spec := model.StatSpec{
+		Level:  78,
+		Nature: "adamant",
+		IVs:    map[string]int{"hp": 24, "attack": 12, "defense": 30, "special-attack": 16, "special-defense": 23, "speed": 5},
+		EVs:    map[string]int{"hp": 74, "attack": 190, "defense": 91, "special-attack": 48, "special-defense": 84, "speed": 23},
+	}
+
+	assert.Equal(t, battleStats(289, 278, 193, 135, 171, 171), pokemon.CalculateStats(garchomp, spec))
+}
+
+func TestApplyStatSpec(t *testing.T) {
+	p := pokemon.New()
+	pikachu := model.Pokemon{Name: "pikachu", Stats: battleStats(35, 55, 40, 50, 50, 90), CombatPower: 53.33}
+
+	result, err := p.ApplyStatSpec(pikachu, model.StatSpec{}, pokemon.MeanCP{})
+	assert.NoError(t, err)
+	assert.Equal(t, &model.StatSpec{Pokemon: "pikachu", Level: pokemon.BattleLevel, Nature: pokemon.DefaultNature}, result.Spec)
+	assert.Equal(t, battleStats(95, 60, 45, 55, 55, 95), result.FinalStats)
+	assert.Equal(t, 67.5, result.CombatPower)
+	assert.Equal(t, pikachu.Stats, result.Stats)
+
+	result, err = p.ApplyStatSpec(pikachu, model.StatSpec{Level: 100, Nature: "timid", IVs: map[string]int{"speed": 31}, EVs: map[string]int{"speed": 252}}, pokemon.MeanCP{})
+	assert.NoError(t, err)
+	assert.Equal(t, battleStats(180, 103, 85, 105, 105, 306), result.FinalStats)
+}
+
+func TestValidateStatSpec(t *testing.T) {
+	testTable := []struct {
+		spec          model.StatSpec
+		expectedError bool
+	}{
+		{spec: model.StatSpec{Level: 100, Nature: "modest", IVs: map[string]int{"special-attack": 31}}, expectedError: false},
+		{spec: model.StatSpec{EVs: map[string]int{"attack": 252, "speed": 252, "hp": 6}}, expectedError: false},
+		{spec: model.StatSpec{Level: 101}, expectedError: true},
+		{spec: model.StatSpec{Level: -1}, expectedError: true},
+		{spec: model.StatSpec{Nature: "grumpy"}, expectedError: true},
+		{spec: model.StatSpec{IVs: map[string]int{"attack": 32}}, expectedError: true},
+		{spec: model.StatSpec{IVs: map[string]int{"luck": 1}}, expectedError: true},
+		{spec: model.StatSpec{EVs: map[string]int{"attack": 253}}, expectedError: true},
+		{spec: model.StatSpec{EVs: map[string]int{"attack": 252, "speed": 252, "hp": 8}}, expectedError: true},
+	}
+
+	for _, test := range testTable {
+		_, err := pokemon.ValidateStatSpec(test.spec)
+		assert.Equal(t, test.expectedError, err != nil, "%+v", test.spec)
+	}
+}
@@ -0,0 +1,219 @@
+package pokemon
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"pokeapi/model"
+	"sort"
+)
+
+const (
+	FightModeTeam = "team"
+
+	TeamResolutionSumCP      = "sum_cp"
+	TeamResolutionSequential = "sequential"
+	TeamResolutionBestOf     = "best_of"
+
+	MaxTeamSize = 6
+)
+
+func IsValidTeamResolution(resolution string) bool {
+	switch resolution {
+	case TeamResolutionSumCP, TeamResolutionSequential, TeamResolutionBestOf:
+		return true
+	}
+	return false
+}
+
+// DefaultBestOf returns the largest odd number of duels teams of the given
+// size can play.
+func DefaultBestOf(size int) int {
+	if size%2 == 0 {
+		return size - 1
+	}
+	return size
+}
+
+type teamMember struct {
+	*battler
+	team int
+	slot int
+}
+
+// TeamFight settles a fight between teams of equal size:
+//
+//   - sum_cp ranks the teams by their total combat power.
+//   - sequential sends the members out in order; the winner of a duel stays
+//     in with the HP it has left until one team has no one left.
+//   - best_of pairs the members slot by slot until a team has won the majority
+//     of bestOf duels.
+//
+// With more than two teams every team meets every other once and teams are
+// ranked by matches won, then by duels won; level teams share a rank. All
+// duels draw from seed, like Battle.
+func (p Pokemon) TeamFight(resolution string, teams [][]model.Pokemon, bestOf int, seed int64) (model.TeamFightResult, error) {
+	if len(teams) < 2 {
+		return model.TeamFightResult{}, fmt.Errorf("a team fight needs at least 2 teams, got %d", len(teams))
+	}
+	size := len(teams[0])
+	for _, team := range teams {
+		if len(team) < 1 || len(team) > MaxTeamSize || len(team) != size {
+			return model.TeamFightResult{}, fmt.Errorf("teams must have the same size, between 1 and %d Pokemon", MaxTeamSize)
+		}
+	}
+
+	switch resolution {
+	case TeamResolutionSumCP, TeamResolutionSequential:
+		bestOf = 0
+	case TeamResolutionBestOf:
+		if bestOf == 0 {
+			bestOf = DefaultBestOf(size)
+		}
+		if bestOf < 1 || bestOf > size || bestOf%2 == 0 {
+			return model.TeamFightResult{}, fmt.Errorf("best_of must be an odd number between 1 and %d", size)
+		}
+	default:
+		return model.TeamFightResult{}, fmt.Errorf("unknown team resolution %q", resolution)
+	}
+
+	result := model.TeamFightResult{
+		Resolution:    resolution,
+		BestOf:        bestOf,
+		Seed:          seed,
+		EngineVersion: EngineVersion,
+	}
+	for t, team := range teams {
+		teamResult := model.TeamResult{Team: t}
+		for slot, member := range team {
+			teamResult.Power += member.CombatPower
+			teamResult.Members = append(teamResult.Members, model.TeamMemberResult{
+				Pokemon:     member.Name,
+				Slot:        slot,
+				CombatPower: member.CombatPower,
+				FinalStats:  member.FinalStats,
+			})
+		}
+		teamResult.Power = math.Round(teamResult.Power*100) / 100
+		result.Teams = append(result.Teams, teamResult)
+	}
+
+	if resolution != TeamResolutionSumCP {
+		rng := rand.New(rand.NewSource(seed))
+		match := 0
+		for a := range teams {
+			for b := a + 1; b < len(teams); b++ {
+				match++
+				var winner int
+				if resolution == TeamResolutionSequential {
+					winner = sequentialMatch(rng, &result, match, teams, a, b)
+				} else {
+					winner = bestOfMatch(rng, &result, match, teams, a, b, bestOf)
+				}
+				result.Teams[winner].MatchWins++
+			}
+		}
+	}
+
+	rankTeams(resolution, result.Teams)
+	return result, nil
+}
+
+func sequentialMatch(rng *rand.Rand, result *model.TeamFightResult, match int, teams [][]model.Pokemon, a int, b int) int {
+	memberA := teamMember{newBattler(teams[a][0]), a, 0}
+	memberB := teamMember{newBattler(teams[b][0]), b, 0}
+	for {
+		_, loser := teamDuel(rng, result, match, memberA, memberB)
+		next := loser.slot + 1
+		if next == len(teams[loser.team]) {
+			if loser.team == a {
+				return b
+			}
+			return a
+		}
+
+		if loser.team == a {
+			memberA = teamMember{newBattler(teams[a][next]), a, next}
+		} else {
+			memberB = teamMember{newBattler(teams[b][next]), b, next}
+		}
+	}
+}
+
+func bestOfMatch(rng *rand.Rand, result *model.TeamFightResult, match int, teams [][]model.Pokemon, a int, b int, bestOf int) int {
+	winsA, winsB := 0, 0
+	for slot := 0; ; slot++ {
+		winner, _ := teamDuel(rng, result, match, teamMember{newBattler(teams[a][slot]), a, slot}, teamMember{newBattler(teams[b][slot]), b, slot})
+		if winner.team == a {
+			winsA++
+		} else {
+			winsB++
+		}
+
+		if winsA > bestOf/2 {
+			return a
+		}
+		if winsB > bestOf/2 {
+			return b
+		}
+	}
+}
+
+// teamDuel runs one duel of a team match and records it on both members,
+// which are told apart by team and slot since teams may field the same
+// Pokémon.
+func teamDuel(rng *rand.Rand, result *model.TeamFightResult, match int, a teamMember, b teamMember) (teamMember, teamMember) {
+	dealtA, dealtB := a.damageDealt, b.damageDealt
+	won, _, turns, _ := duel(rng, a.battler, b.battler)
+	winner, loser := a, b
+	if won != a.battler {
+		winner, loser = b, a
+	}
+
+	result.Teams[a.team].Members[a.slot].DamageDealt += a.damageDealt - dealtA
+	result.Teams[b.team].Members[b.slot].DamageDealt += b.damageDealt - dealtB
+
+	winnerResult := &result.Teams[winner.team].Members[winner.slot]
+	winnerResult.Duels++
+	winnerResult.Wins++
+	loserResult := &result.Teams[loser.team].Members[loser.slot]
+	loserResult.Duels++
+	loserResult.Losses++
+	result.Teams[winner.team].DuelWins++
+
+	result.Duels = append(result.Duels, model.TeamDuel{
+		Match:      match,
+		TeamA:      a.team,
+		TeamB:      b.team,
+		PokemonA:   a.pokemon.Name,
+		PokemonB:   b.pokemon.Name,
+		Winner:     winner.pokemon.Name,
+		WinnerTeam: winner.team,
+		Turns:      turns,
+	})
+	return winner, loser
+}
+
+func rankTeams(resolution string, teams []model.TeamResult) {
+	key := func(t model.TeamResult) [2]float64 {
+		if resolution == TeamResolutionSumCP {
+			return [2]float64{t.Power, 0}
+		}
+		return [2]float64{float64(t.MatchWins), float64(t.DuelWins)}
+	}
+
+	sort.SliceStable(teams, func(i, j int) bool {
+		ki, kj := key(teams[i]), key(teams[j])
+		if ki[0] != kj[0] {
+			return ki[0] > kj[0]
+		}
+		return ki[1] > kj[1]
+	})
+	for i := range teams {
+		if i > 0 && key(teams[i]) == key(teams[i-1]) {
+			teams[i].Rank = teams[i-1].Rank
+		} else {
+			teams[i].Rank = i + 1
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package pokemon_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func teamPokemon(name string, stat int, cp float64) model.Pokemon {
+	return model.Pokemon{Name: name, Types: []string{"normal"}, Stats: battleStats(stat, stat, stat, stat, stat, stat), CombatPower: cp}
+}
+
+func TestTeamFightSumCP(t *testing.T) {
+	p := pokemon.New()
+	teams := [][]model.Pokemon{
+		{teamPokemon("a1", 50, 40), teamPokemon("a2", 50, 60)},
+		{teamPokemon("b1", 50, 90), teamPokemon("b2", 50, 30)},
+		{teamPokemon("c1", 50, 70), teamPokemon("c2", 50, 30)},
+	}
+
+	result, err := p.TeamFight(pokemon.TeamResolutionSumCP, teams, 0, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Duels)
+
+	testTable := []struct {
+		expectedTeam  int
+		expectedRank  int
+		expectedPower float64
+	}{
+		{expectedTeam: 1, expectedRank: 1, expectedPower: 120},
+		{expectedTeam: 0, expectedRank: 2, expectedPower: 100},
+		{expectedTeam: 2, expectedRank: 2, expectedPower: 100},
+	}
+	for i, test := range testTable {
+		assert.Equal(t, test.expectedTeam, result.Teams[i].Team)
+		assert.Equal(t, test.expectedRank, result.Teams[i].Rank)
+		assert.Equal(t, test.expectedPower, result.Teams[i].Power)
+	}
+}
+
+func TestTeamFightSequential(t *testing.T) {
+	p := pokemon.New()
+	teams := [][]model.Pokemon{
+		{teamPokemon("weak1", 20, 0), teamPokemon("weak2", 20, 0)},
+		{teamPokemon("strong", 200, 0), teamPokemon("weak3", 20, 0)},
+	}
+
+	result, err := p.TeamFight(pokemon.TeamResolutionSequential, teams, 0, 5)
+	assert.NoError(t, err)
+
+	// strong stays in after the first duel and beats both opponents, weak3
+	// never has to fight
+	assert.Len(t, result.Duels, 2)
+	assert.Equal(t, 1, result.Teams[0].Team)
+	assert.Equal(t, 1, result.Teams[0].Rank)
+	assert.Equal(t, 1, result.Teams[0].MatchWins)
+	assert.Equal(t, 2, result.Teams[0].DuelWins)
+	assert.Equal(t, 2, result.Teams[0].Members[0].Wins)
+	assert.Equal(t, 0, result.Teams[0].Members[1].Duels)
+	assert.Greater(t, result.Teams[0].Members[0].DamageDealt, 0)
+	assert.Equal(t, 2, result.Teams[1].Rank)
+	assert.Equal(t, 1, result.Teams[1].Members[0].Losses)
+	assert.Equal(t, 1, result.Teams[1].Members[1].Losses)
+
+	replayed, err := p.TeamFight(pokemon.TeamResolutionSequential, teams, 0, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, result, replayed)
+}
+
+func TestTeamFightBestOf(t *testing.T) {
+	p := pokemon.New()
+	teams := [][]model.Pokemon{
+		{teamPokemon("a1", 200, 0), teamPokemon("a2", 200, 0), teamPokemon("a3", 20, 0), teamPokemon("a4", 20, 0)},
+		{teamPokemon("b1", 20, 0), teamPokemon("b2", 20, 0), teamPokemon("b3", 200, 0), teamPokemon("b4", 200, 0)},
+	}
+
+	result, err := p.TeamFight(pokemon.TeamResolutionBestOf, teams, 0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.BestOf)
+	assert.Len(t, result.Duels, 2)
+	assert.Equal(t, 0, result.Teams[0].Team)
+	assert.Equal(t, 2, result.Teams[0].DuelWins)
+
+	result, err = p.TeamFight(pokemon.TeamResolutionBestOf, teams, 1, 3)
+	assert.NoError(t, err)
+	assert.Len(t, result.Duels, 1)
+}
+
+func TestTeamFightRoundRobin(t *testing.T) {
+	p := pokemon.New()
+	teams := [][]model.Pokemon{
+		{teamPokemon("weak", 20, 0)},
+		{teamPokemon("strong", 200, 0)},
+		{teamPokemon("medium", 80, 0)},
+	}
+
+	result, err := p.TeamFight(pokemon.TeamResolutionSequential, teams, 0, 9)
+	assert.NoError(t, err)
+	assert.Len(t, result.Duels, 3)
+	for i, team := range []int{1, 2, 0} {
+		assert.Equal(t, team, result.Teams[i].Team)
+		assert.Equal(t, i+1, result.Teams[i].Rank)
+		assert.Equal(t, 2-i, result.Teams[i].MatchWins)
+	}
+}
+
+func TestTeamFightInvalid(t *testing.T) {
+	p := pokemon.New()
+	pair := []model.Pokemon{teamPokemon("a", 50, 0), teamPokemon("b", 50, 0)}
+	single := []model.Pokemon{teamPokemon("c", 50, 0)}
+
+	testTable := []struct {
+		resolution string
+		teams      [][]model.Pokemon
+		bestOf     int
+	}{
+		{resolution: pokemon.TeamResolutionSumCP, teams: [][]model.Pokemon{pair}},
+		{resolution: pokemon.TeamResolutionSumCP, teams: [][]model.Pokemon{pair, single}},
+		{resolution: pokemon.TeamResolutionBestOf, teams: [][]model.Pokemon{pair, pair}, bestOf: 2},
+		{resolution: pokemon.TeamResolutionBestOf, teams: [][]model.Pokemon{single, single}, bestOf: 3},
+		{resolution: "coin_flip", teams: [][]model.Pokemon{pair, pair}},
+	}
+	for _, test := range testTable {
+		_, err := p.TeamFight(test.resolution, test.teams, test.bestOf, 1)
+		assert.Error(t, err, test.resolution)
+	}
+}
+
+func TestTeamFightMirrorMatch(t *testing.T) {
+	p := pokemon.New()
+	teams := [][]model.Pokemon{
+		{teamPokemon("pikachu", 50, 0)},
+		{teamPokemon("pikachu", 50, 0)},
+	}
+
+	result, err := p.TeamFight(pokemon.TeamResolutionSequential, teams, 0, 7)
+	assert.NoError(t, err)
+	assert.Len(t, result.Duels, 1)
+
+	// both members fought, so both dealt damage, though they share a name
+	winner, loser := result.Teams[0], result.Teams[1]
+	assert.Equal(t, winner.Team, result.Duels[0].WinnerTeam)
+	assert.Equal(t, 1, winner.Members[0].Wins)
+	assert.Equal(t, 1, loser.Members[0].Losses)
+	assert.Greater(t, winner.Members[0].DamageDealt, 0)
+	assert.Greater(t, loser.Members[0].DamageDealt, 0)
+}
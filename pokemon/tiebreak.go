@@ -0,0 +1,135 @@
+package pokemon
+
+import (
+	"fmt"
+	"math/rand"
+	"pokeapi/model"
+	"sort"
+	"strings"
+)
+
+const (
+	TieBreakSpeed    = "speed"
+	TieBreakHP       = "hp"
+	TieBreakPokedex  = "pokedex"
+	TieBreakCoinFlip = "coin-flip"
+
+	// TieBreakNone configures no tie breakers, so every tie is shared.
+	TieBreakNone = "none"
+)
+
+// DefaultTieBreakers settles a tie on power by the higher speed, then the
+// higher HP, then the lower Pokédex number and finally a coin flip drawn from
+// the fight's seed.
+var DefaultTieBreakers = []string{TieBreakSpeed, TieBreakHP, TieBreakPokedex, TieBreakCoinFlip}
+
+// ParseTieBreakers reads a comma separated list of tie breakers. An empty
+// list is DefaultTieBreakers and "none" is no tie breakers at all.
+func ParseTieBreakers(tieBreakers string) ([]string, error) {
+	if strings.TrimSpace(tieBreakers) == "" {
+		return DefaultTieBreakers, nil
+	}
+	if strings.TrimSpace(tieBreakers) == TieBreakNone {
+		return []string{}, nil
+	}
+
+	var parsed []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(tieBreakers, ",") {
+		t = strings.TrimSpace(t)
+		switch t {
+		case TieBreakSpeed, TieBreakHP, TieBreakPokedex, TieBreakCoinFlip:
+		default:
+			return nil, fmt.Errorf("unknown tie breaker %q", t)
+		}
+		if seen[t] {
+			return nil, fmt.Errorf("tie breaker %q is listed twice", t)
+		}
+		seen[t] = true
+		parsed = append(parsed, t)
+	}
+	return parsed, nil
+}
+
+func FormatTieBreakers(tieBreakers []string) string {
+	if len(tieBreakers) == 0 {
+		return TieBreakNone
+	}
+	return strings.Join(tieBreakers, ",")
+}
+
+// rankPokemon orders the Pokémon by power, highest first, settles equal power
+// with the tie breakers in order and sets each Pokémon's Rank and TieBreak.
+// Pokémon still level after every tie breaker share a rank and are listed by
+// name, so the result does not depend on the order they came in.
+func rankPokemon(pokemons []model.Pokemon, power func(model.Pokemon) float64, tieBreakers []string, seed int64) []model.Pokemon {
+	sort.SliceStable(pokemons, func(i, j int) bool {
+		return pokemons[i].Name < pokemons[j].Name
+	})
+
+	coins := make(map[string]float64, len(pokemons))
+	rng := rand.New(rand.NewSource(seed))
+	for _, p := range pokemons {
+		coins[p.Name] = rng.Float64()
+	}
+
+	// compare reports whether a places ahead of b (-1), behind it (1) or
+	// level with it (0), and the tie breaker that decided.
+	compare := func(a model.Pokemon, b model.Pokemon) (int, string) {
+		if power(a) != power(b) {
+			if power(a) > power(b) {
+				return -1, ""
+			}
+			return 1, ""
+		}
+
+		for _, t := range tieBreakers {
+			var va, vb float64
+			switch t {
+			case TieBreakSpeed, TieBreakHP:
+				va, vb = float64(statValue(a, t)), float64(statValue(b, t))
+			case TieBreakPokedex:
+				// a lower Pokédex number places ahead
+				va, vb = float64(-a.ID), float64(-b.ID)
+			case TieBreakCoinFlip:
+				va, vb = coins[a.Name], coins[b.Name]
+			}
+			if va > vb {
+				return -1, t
+			}
+			if va < vb {
+				return 1, t
+			}
+		}
+		return 0, ""
+	}
+
+	sort.SliceStable(pokemons, func(i, j int) bool {
+		c, _ := compare(pokemons[i], pokemons[j])
+		return c < 0
+	})
+	for i := range pokemons {
+		pokemons[i].Rank = i + 1
+		pokemons[i].TieBreak = ""
+		if i == 0 {
+			continue
+		}
+		c, tieBreak := compare(pokemons[i-1], pokemons[i])
+		if c == 0 {
+			pokemons[i].Rank = pokemons[i-1].Rank
+		}
+		pokemons[i].TieBreak = tieBreak
+	}
+	return pokemons
+}
+
+// statValue is a stat the Pokémon fights with: its final stat once a spec is
+// applied, its base stat otherwise.
+func statValue(pokemon model.Pokemon, name string) int {
+	for _, s := range cpStats(pokemon) {
+		if s.Name == name {
+			return s.Value
+		}
+	}
+	return 0
+}
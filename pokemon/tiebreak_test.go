@@ -0,0 +1,98 @@
+package pokemon_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func TestFightTieBreakers(t *testing.T) {
+	p := pokemon.New()
+	// equal CP throughout, so only the tie breakers can tell them apart
+	pokemons := func() []model.Pokemon {
+		return []model.Pokemon{
+			{ID: 25, Name: "pikachu", CombatPower: 50, Stats: battleStats(35, 55, 40, 50, 50, 90)},
+			{ID: 7, Name: "squirtle", CombatPower: 50, Stats: battleStats(44, 48, 65, 50, 64, 43)},
+			{ID: 4, Name: "charmander", CombatPower: 50, Stats: battleStats(39, 52, 43, 60, 50, 43)},
+			{ID: 1, Name: "bulbasaur", CombatPower: 50, Stats: battleStats(44, 49, 49, 65, 65, 43)},
+		}
+	}
+	testTable := []struct {
+		tieBreakers       string
+		expectedNames     []string
+		expectedRanks     []int
+		expectedTieBreaks []string
+	}{
+		{
+			tieBreakers:       "speed,hp,pokedex",
+			expectedNames:     []string{"pikachu", "bulbasaur", "squirtle", "charmander"},
+			expectedRanks:     []int{1, 2, 3, 4},
+			expectedTieBreaks: []string{"", "speed", "pokedex", "hp"},
+		},
+		{
+			tieBreakers:       "speed,hp",
+			expectedNames:     []string{"pikachu", "bulbasaur", "squirtle", "charmander"},
+			expectedRanks:     []int{1, 2, 2, 4},
+			expectedTieBreaks: []string{"", "speed", "", "hp"},
+		},
+		{
+			tieBreakers:       "none",
+			expectedNames:     []string{"bulbasaur", "charmander", "pikachu", "squirtle"},
+			expectedRanks:     []int{1, 1, 1, 1},
+			expectedTieBreaks: []string{"", "", "", ""},
+		},
+	}
+
+	for _, test := range testTable {
+		tieBreakers, err := pokemon.ParseTieBreakers(test.tieBreakers)
+		assert.NoError(t, err)
+
+		result := p.FightPokemon(pokemons(), tieBreakers, 1)
+		var names, tieBreaks []string
+		var ranks []int
+		for _, r := range result {
+			names = append(names, r.Name)
+			ranks = append(ranks, r.Rank)
+			tieBreaks = append(tieBreaks, r.TieBreak)
+		}
+		assert.Equal(t, test.expectedNames, names, test.tieBreakers)
+		assert.Equal(t, test.expectedRanks, ranks, test.tieBreakers)
+		assert.Equal(t, test.expectedTieBreaks, tieBreaks, test.tieBreakers)
+	}
+}
+
+func TestFightCoinFlip(t *testing.T) {
+	p := pokemon.New()
+	pokemons := []model.Pokemon{
+		{Name: "pikachu", CombatPower: 50},
+		{Name: "squirtle", CombatPower: 50},
+		{Name: "charmander", CombatPower: 50},
+	}
+	reversed := []model.Pokemon{pokemons[2], pokemons[1], pokemons[0]}
+
+	// the same seed gives the same order whatever order the Pokémon came in
+	first := p.FightPokemon(append([]model.Pokemon(nil), pokemons...), []string{pokemon.TieBreakCoinFlip}, 42)
+	second := p.FightPokemon(reversed, []string{pokemon.TieBreakCoinFlip}, 42)
+	assert.Equal(t, first, second)
+	for i, r := range first {
+		assert.Equal(t, i+1, r.Rank)
+	}
+}
+
+func TestParseTieBreakers(t *testing.T) {
+	tieBreakers, err := pokemon.ParseTieBreakers("")
+	assert.NoError(t, err)
+	assert.Equal(t, pokemon.DefaultTieBreakers, tieBreakers)
+	assert.Equal(t, "speed,hp,pokedex,coin-flip", pokemon.FormatTieBreakers(tieBreakers))
+
+	tieBreakers, err = pokemon.ParseTieBreakers("none")
+	assert.NoError(t, err)
+	assert.Empty(t, tieBreakers)
+	assert.Equal(t, "none", pokemon.FormatTieBreakers(tieBreakers))
+
+	for _, invalid := range []string{"attack", "speed,speed", "speed,,hp"} {
+		_, err := pokemon.ParseTieBreakers(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,526 @@
+package pokemon
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	TournamentSingleElimination = "single_elimination"
+	TournamentDoubleElimination = "double_elimination"
+	TournamentRoundRobin        = "round_robin"
+	TournamentSwiss             = "swiss"
+
+	BracketMain    = "main"
+	BracketWinners = "winners"
+	BracketLosers  = "losers"
+	BracketFinal   = "final"
+)
+
+func IsValidTournamentFormat(format string) bool {
+	switch format {
+	case TournamentSingleElimination, TournamentDoubleElimination, TournamentRoundRobin, TournamentSwiss:
+		return true
+	}
+	return false
+}
+
+type TournamentEntrant struct {
+	Pokemon string
+	Seed    int
+}
+
+// TournamentMatch is one pairing of a round. A match without PokemonB is a
+// bye, won by PokemonA without a fight.
+type TournamentMatch struct {
+	Round    int
+	Position int
+	Bracket  string
+	PokemonA string
+	PokemonB string
+	Winner   string
+}
+
+func (m TournamentMatch) Bye() bool {
+	return m.PokemonB == ""
+}
+
+func (m TournamentMatch) Loser() string {
+	if m.Bye() || m.Winner == "" {
+		return ""
+	}
+	if m.Winner == m.PokemonA {
+		return m.PokemonB
+	}
+	return m.PokemonA
+}
+
+type TournamentStanding struct {
+	Rank     int    `json:"rank"`
+	Pokemon  string `json:"pokemon"`
+	Seed     int    `json:"seed"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Byes     int    `json:"byes"`
+	Points   int    `json:"points"`
+	Buchholz int    `json:"buchholz"`
+}
+
+// Tournament derives every round from the entrants and the matches played so
+// far, so its state can be rebuilt from storage at any time. Every match of
+// the previous rounds must have a winner before the next round is drawn.
+// Rounds only applies to Swiss and defaults to SwissRounds.
+type Tournament struct {
+	Format   string
+	Rounds   int
+	Entrants []TournamentEntrant
+	Matches  []TournamentMatch
+}
+
+// SwissRounds is the number of Swiss rounds needed to leave a single entrant
+// with a perfect record: log2 of the entrants, rounded up.
+func SwissRounds(entrants int) int {
+	rounds := 0
+	for size := 1; size < entrants; size *= 2 {
+		rounds++
+	}
+	return rounds
+}
+
+// NextRound draws the next round, with byes already decided, or returns nil
+// once the tournament is over.
+func (t Tournament) NextRound() []TournamentMatch {
+	entrants := t.seeded()
+	if len(entrants) < 2 {
+		return nil
+	}
+
+	switch t.Format {
+	case TournamentSingleElimination:
+		return t.nextSingleElimination(entrants)
+	case TournamentDoubleElimination:
+		return t.nextDoubleElimination(entrants)
+	case TournamentRoundRobin:
+		return t.nextRoundRobin(entrants)
+	case TournamentSwiss:
+		return t.nextSwiss(entrants)
+	}
+	return nil
+}
+
+func (t Tournament) Finished() bool {
+	return len(t.Matches) > 0 && t.NextRound() == nil
+}
+
+// Standings ranks entrants by how far they got in elimination formats, by
+// wins in round robin and by points, then Buchholz, in Swiss. A win or a bye
+// is worth a point; Buchholz sums the points of every opponent faced.
+// Entrants that can't be separated share a rank.
+func (t Tournament) Standings() []TournamentStanding {
+	entrants := t.seeded()
+	index := make(map[string]int, len(entrants))
+	standings := make([]TournamentStanding, len(entrants))
+	for i, e := range entrants {
+		index[e.Pokemon] = i
+		standings[i] = TournamentStanding{Pokemon: e.Pokemon, Seed: e.Seed}
+	}
+
+	eliminated := make([]int, len(entrants))
+	for i := range eliminated {
+		eliminated[i] = math.MaxInt
+	}
+	opponents := make([][]int, len(entrants))
+
+	for _, m := range t.sortedMatches() {
+		if m.Winner == "" {
+			continue
+		}
+		if m.Bye() {
+			standings[index[m.PokemonA]].Byes++
+			continue
+		}
+		winner, loser := index[m.Winner], index[m.Loser()]
+		standings[winner].Wins++
+		standings[loser].Losses++
+		opponents[winner] = append(opponents[winner], loser)
+		opponents[loser] = append(opponents[loser], winner)
+
+		if t.Format == TournamentSingleElimination || standings[loser].Losses == 2 {
+			eliminated[loser] = m.Round
+		}
+	}
+
+	for i := range standings {
+		standings[i].Points = standings[i].Wins + standings[i].Byes
+	}
+	keys := make([][2]int, len(entrants))
+	for i := range standings {
+		for _, o := range opponents[i] {
+			standings[i].Buchholz += standings[o].Points
+		}
+
+		switch t.Format {
+		case TournamentSingleElimination, TournamentDoubleElimination:
+			keys[i] = [2]int{eliminated[i]}
+		case TournamentRoundRobin:
+			keys[i] = [2]int{standings[i].Wins}
+		case TournamentSwiss:
+			keys[i] = [2]int{standings[i].Points, standings[i].Buchholz}
+		}
+	}
+
+	order := make([]int, len(entrants))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := keys[order[i]], keys[order[j]]
+		if a[0] != b[0] {
+			return a[0] > b[0]
+		}
+		return a[1] > b[1]
+	})
+
+	ranked := make([]TournamentStanding, len(order))
+	for i, o := range order {
+		ranked[i] = standings[o]
+		if i > 0 && keys[o] == keys[order[i-1]] {
+			ranked[i].Rank = ranked[i-1].Rank
+		} else {
+			ranked[i].Rank = i + 1
+		}
+	}
+	return ranked
+}
+
+// Champion is the sole winner of a finished tournament, or empty while it is
+// running or when the top rank is shared.
+func (t Tournament) Champion() string {
+	if !t.Finished() {
+		return ""
+	}
+	standings := t.Standings()
+	if len(standings) > 1 && standings[1].Rank == 1 {
+		return ""
+	}
+	return standings[0].Pokemon
+}
+
+func (t Tournament) seeded() []TournamentEntrant {
+	entrants := append([]TournamentEntrant(nil), t.Entrants...)
+	sort.SliceStable(entrants, func(i, j int) bool {
+		return entrants[i].Seed < entrants[j].Seed
+	})
+	return entrants
+}
+
+func (t Tournament) sortedMatches() []TournamentMatch {
+	matches := append([]TournamentMatch(nil), t.Matches...)
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Round != matches[j].Round {
+			return matches[i].Round < matches[j].Round
+		}
+		return matches[i].Position < matches[j].Position
+	})
+	return matches
+}
+
+func (t Tournament) lastRound() int {
+	round := 0
+	for _, m := range t.Matches {
+		if m.Round > round {
+			round = m.Round
+		}
+	}
+	return round
+}
+
+// BracketOrder lists the seeds of a bracket of size entrants in slot order,
+// so that 1 meets 2 only in the final, e.g. 1, 8, 4, 5, 2, 7, 3, 6.
+func BracketOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+func (t Tournament) nextSingleElimination(entrants []TournamentEntrant) []TournamentMatch {
+	if len(t.Matches) == 0 {
+		size := 1
+		for size < len(entrants) {
+			size *= 2
+		}
+		order := BracketOrder(size)
+		var matches []TournamentMatch
+		for i := 0; i < size; i += 2 {
+			match := TournamentMatch{Round: 1, Position: i / 2, Bracket: BracketMain, PokemonA: entrants[order[i]-1].Pokemon}
+			if order[i+1] <= len(entrants) {
+				match.PokemonB = entrants[order[i+1]-1].Pokemon
+			} else {
+				match.Winner = match.PokemonA
+			}
+			matches = append(matches, match)
+		}
+		return matches
+	}
+
+	round := t.lastRound()
+	var previous []TournamentMatch
+	for _, m := range t.sortedMatches() {
+		if m.Round == round {
+			previous = append(previous, m)
+		}
+	}
+	if len(previous) < 2 {
+		return nil
+	}
+
+	var matches []TournamentMatch
+	for i := 0; i+1 < len(previous); i += 2 {
+		matches = append(matches, TournamentMatch{
+			Round:    round + 1,
+			Position: i / 2,
+			Bracket:  BracketMain,
+			PokemonA: previous[i].Winner,
+			PokemonB: previous[i+1].Winner,
+		})
+	}
+	return matches
+}
+
+// nextDoubleElimination pairs the entrants without a loss among themselves and
+// those with one loss among themselves; a second loss eliminates. The last
+// unbeaten entrant meets the last one-loss entrant in the final, which is
+// replayed when the unbeaten one loses it.
+func (t Tournament) nextDoubleElimination(entrants []TournamentEntrant) []TournamentMatch {
+	losses := make(map[string]int)
+	for _, m := range t.Matches {
+		if loser := m.Loser(); loser != "" {
+			losses[loser]++
+		}
+	}
+
+	var winners, losers []TournamentEntrant
+	for _, e := range entrants {
+		switch losses[e.Pokemon] {
+		case 0:
+			winners = append(winners, e)
+		case 1:
+			losers = append(losers, e)
+		}
+	}
+
+	round := t.lastRound() + 1
+	switch {
+	case len(winners)+len(losers) < 2:
+		return nil
+	case len(winners) == 1 && len(losers) == 1:
+		return []TournamentMatch{{Round: round, Bracket: BracketFinal, PokemonA: winners[0].Pokemon, PokemonB: losers[0].Pokemon}}
+	case len(winners) == 0 && len(losers) == 2:
+		return []TournamentMatch{{Round: round, Bracket: BracketFinal, PokemonA: losers[0].Pokemon, PokemonB: losers[1].Pokemon}}
+	}
+
+	var matches []TournamentMatch
+	if len(winners) > 1 {
+		matches = append(matches, t.pair(winners, round, BracketWinners, 0)...)
+	}
+	if len(losers) > 1 {
+		matches = append(matches, t.pair(losers, round, BracketLosers, len(matches))...)
+	}
+	return matches
+}
+
+func (t Tournament) nextRoundRobin(entrants []TournamentEntrant) []TournamentMatch {
+	names := make([]string, 0, len(entrants)+1)
+	for _, e := range entrants {
+		names = append(names, e.Pokemon)
+	}
+	if len(names)%2 == 1 {
+		names = append(names, "")
+	}
+
+	round := t.lastRound() + 1
+	if round > len(names)-1 {
+		return nil
+	}
+
+	// circle method: the first entrant stays put while the others rotate
+	rotated := make([]string, len(names))
+	rotated[0] = names[0]
+	for i := 1; i < len(names); i++ {
+		rotated[i] = names[1+(i-1+round-1)%(len(names)-1)]
+	}
+
+	var matches []TournamentMatch
+	for i := 0; i < len(rotated)/2; i++ {
+		a, b := rotated[i], rotated[len(rotated)-1-i]
+		if a == "" {
+			a, b = b, a
+		}
+		match := TournamentMatch{Round: round, Position: i, Bracket: BracketMain, PokemonA: a, PokemonB: b}
+		if b == "" {
+			match.Winner = a
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// nextSwiss pairs entrants with equal points, falling back to the closest
+// record, without rematches. With an odd count the lowest ranked entrant
+// without a bye so far gets one.
+func (t Tournament) nextSwiss(entrants []TournamentEntrant) []TournamentMatch {
+	rounds := t.Rounds
+	if rounds <= 0 {
+		rounds = SwissRounds(len(entrants))
+	}
+	if rounds > len(entrants)-1+len(entrants)%2 {
+		rounds = len(entrants) - 1 + len(entrants)%2
+	}
+	round := t.lastRound() + 1
+	if round > rounds {
+		return nil
+	}
+
+	met := make(map[[2]string]bool)
+	byes := make(map[string]bool)
+	for _, m := range t.Matches {
+		if m.Bye() {
+			byes[m.PokemonA] = true
+			continue
+		}
+		met[[2]string{m.PokemonA, m.PokemonB}] = true
+		met[[2]string{m.PokemonB, m.PokemonA}] = true
+	}
+
+	var ranked []string
+	for _, s := range t.Standings() {
+		ranked = append(ranked, s.Pokemon)
+	}
+
+	var matches []TournamentMatch
+	if len(ranked)%2 == 1 {
+		bye := len(ranked) - 1
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if !byes[ranked[i]] {
+				bye = i
+				break
+			}
+		}
+		matches = append(matches, TournamentMatch{
+			Round:    round,
+			Bracket:  BracketMain,
+			PokemonA: ranked[bye],
+			Winner:   ranked[bye],
+		})
+		ranked = append(ranked[:bye:bye], ranked[bye+1:]...)
+	}
+
+	budget := swissPairingBudget
+	pairs, ok := swissPairs(ranked, met, &budget)
+	if !ok {
+		budget = swissPairingBudget
+		pairs, _ = swissPairs(ranked, map[[2]string]bool{}, &budget)
+	}
+	for _, p := range pairs {
+		matches = append(matches, TournamentMatch{
+			Round:    round,
+			Position: len(matches),
+			Bracket:  BracketMain,
+			PokemonA: p[0],
+			PokemonB: p[1],
+		})
+	}
+	return matches
+}
+
+// swissPairingBudget caps the backtracking of swissPairs; when no pairing
+// without rematches turns up in time, rematches are allowed.
+const swissPairingBudget = 100000
+
+// swissPairs pairs the best ranked entrant with the next best one it hasn't
+// met, backtracking when that leaves the rest impossible to pair.
+func swissPairs(ranked []string, met map[[2]string]bool, budget *int) ([][2]string, bool) {
+	if len(ranked) == 0 {
+		return nil, true
+	}
+	*budget--
+	if *budget < 0 {
+		return nil, false
+	}
+
+	for j := 1; j < len(ranked); j++ {
+		if met[[2]string{ranked[0], ranked[j]}] {
+			continue
+		}
+		rest := make([]string, 0, len(ranked)-2)
+		rest = append(rest, ranked[1:j]...)
+		rest = append(rest, ranked[j+1:]...)
+		if pairs, ok := swissPairs(rest, met, budget); ok {
+			return append([][2]string{{ranked[0], ranked[j]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// pair matches the best remaining seed with the worst one it hasn't met yet.
+// With an odd count the best seed without a bye so far sits the round out.
+func (t Tournament) pair(entrants []TournamentEntrant, round int, bracket string, position int) []TournamentMatch {
+	met := make(map[[2]string]bool)
+	byes := make(map[string]bool)
+	for _, m := range t.Matches {
+		if m.Bye() {
+			byes[m.PokemonA] = true
+			continue
+		}
+		met[[2]string{m.PokemonA, m.PokemonB}] = true
+		met[[2]string{m.PokemonB, m.PokemonA}] = true
+	}
+
+	var matches []TournamentMatch
+	remaining := append([]TournamentEntrant(nil), entrants...)
+	if len(remaining)%2 == 1 {
+		bye := 0
+		for i, e := range remaining {
+			if !byes[e.Pokemon] {
+				bye = i
+				break
+			}
+		}
+		matches = append(matches, TournamentMatch{
+			Round:    round,
+			Position: position,
+			Bracket:  bracket,
+			PokemonA: remaining[bye].Pokemon,
+			Winner:   remaining[bye].Pokemon,
+		})
+		position++
+		remaining = append(remaining[:bye], remaining[bye+1:]...)
+	}
+
+	for len(remaining) > 0 {
+		a := remaining[0]
+		opponent := len(remaining) - 1
+		for j := len(remaining) - 1; j > 0; j-- {
+			if !met[[2]string{a.Pokemon, remaining[j].Pokemon}] {
+				opponent = j
+				break
+			}
+		}
+		matches = append(matches, TournamentMatch{
+			Round:    round,
+			Position: position,
+			Bracket:  bracket,
+			PokemonA: a.Pokemon,
+			PokemonB: remaining[opponent].Pokemon,
+		})
+		position++
+		remaining = append(remaining[1:opponent], remaining[opponent+1:]...)
+	}
+	return matches
+}
@@ -0,0 +1,214 @@
+package pokemon_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+// playTournament resolves every match in favour of the lower seed, except for
+// the first meeting of a pairing listed in upsets.
+func playTournament(t *testing.T, format string, n int, upsets map[[2]string]bool) pokemon.Tournament {
+	tournament := pokemon.Tournament{Format: format}
+	seeds := make(map[string]int)
+	for i := 1; i <= n; i++ {
+		name := fmt.Sprintf("p%d", i)
+		seeds[name] = i
+		tournament.Entrants = append(tournament.Entrants, pokemon.TournamentEntrant{Pokemon: name, Seed: i})
+	}
+
+	for rounds := 0; ; rounds++ {
+		if !assert.Less(t, rounds, 4*n, "tournament does not finish") {
+			return tournament
+		}
+		matches := tournament.NextRound()
+		if matches == nil {
+			return tournament
+		}
+		for i := range matches {
+			if matches[i].Bye() {
+				assert.Equal(t, matches[i].PokemonA, matches[i].Winner)
+				continue
+			}
+			a, b := matches[i].PokemonA, matches[i].PokemonB
+			assert.NotEqual(t, a, b)
+			winner := a
+			if seeds[b] < seeds[a] {
+				winner = b
+			}
+			if upsets[[2]string{a, b}] || upsets[[2]string{b, a}] {
+				delete(upsets, [2]string{a, b})
+				delete(upsets, [2]string{b, a})
+				if winner == a {
+					winner = b
+				} else {
+					winner = a
+				}
+			}
+			matches[i].Winner = winner
+		}
+		tournament.Matches = append(tournament.Matches, matches...)
+	}
+}
+
+func TestBracketOrder(t *testing.T) {
+	assert.Equal(t, []int{1}, pokemon.BracketOrder(1))
+	assert.Equal(t, []int{1, 4, 2, 3}, pokemon.BracketOrder(4))
+	assert.Equal(t, []int{1, 8, 4, 5, 2, 7, 3, 6}, pokemon.BracketOrder(8))
+}
+
+func TestSingleElimination(t *testing.T) {
+	tournament := playTournament(t, pokemon.TournamentSingleElimination, 6, nil)
+
+	firstRound := tournament.Matches[:4]
+	assert.True(t, firstRound[0].Bye())
+	assert.Equal(t, "p1", firstRound[0].PokemonA)
+	assert.Equal(t, "p4", firstRound[1].PokemonA)
+	assert.Equal(t, "p5", firstRound[1].PokemonB)
+	assert.True(t, firstRound[2].Bye())
+	assert.Equal(t, "p2", firstRound[2].PokemonA)
+
+	assert.True(t, tournament.Finished())
+	assert.Equal(t, "p1", tournament.Champion())
+
+	standings := tournament.Standings()
+	var ranks []int
+	for _, s := range standings {
+		ranks = append(ranks, s.Rank)
+	}
+	assert.Equal(t, []int{1, 2, 3, 3, 5, 5}, ranks)
+	assert.Equal(t, "p2", standings[1].Pokemon)
+}
+
+func TestDoubleElimination(t *testing.T) {
+	tournament := playTournament(t, pokemon.TournamentDoubleElimination, 4, map[[2]string]bool{{"p1", "p2"}: true})
+	assert.True(t, tournament.Finished())
+
+	losses := make(map[string]int)
+	finals := 0
+	for _, m := range tournament.Matches {
+		if m.Bracket == pokemon.BracketFinal {
+			finals++
+		}
+		if loser := m.Loser(); loser != "" {
+			losses[loser]++
+		}
+	}
+	// p2 beats p1 in the winners bracket, p1 comes back through the losers
+	// bracket and wins the first final, so the final is played again
+	assert.Equal(t, 2, finals)
+	assert.Equal(t, 1, losses["p1"])
+	assert.Equal(t, 2, losses["p2"])
+	assert.Equal(t, "p1", tournament.Champion())
+	for _, s := range tournament.Standings()[1:] {
+		assert.Equal(t, 2, s.Losses, s.Pokemon)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	for _, n := range []int{4, 5} {
+		tournament := playTournament(t, pokemon.TournamentRoundRobin, n, nil)
+		assert.True(t, tournament.Finished())
+
+		met := make(map[[2]string]int)
+		for _, m := range tournament.Matches {
+			if !m.Bye() {
+				met[[2]string{m.PokemonA, m.PokemonB}]++
+				met[[2]string{m.PokemonB, m.PokemonA}]++
+			}
+		}
+		assert.Len(t, met, n*(n-1), "every pair meets")
+		for pair, count := range met {
+			assert.Equal(t, 1, count, pair)
+		}
+
+		standings := tournament.Standings()
+		for i, s := range standings {
+			assert.Equal(t, i+1, s.Rank)
+			assert.Equal(t, n-1-i, s.Wins)
+		}
+	}
+}
+
+func TestSwissRounds(t *testing.T) {
+	assert.Equal(t, 1, pokemon.SwissRounds(2))
+	assert.Equal(t, 3, pokemon.SwissRounds(8))
+	assert.Equal(t, 4, pokemon.SwissRounds(9))
+}
+
+func TestSwiss(t *testing.T) {
+	for _, n := range []int{7, 8} {
+		tournament := playTournament(t, pokemon.TournamentSwiss, n, nil)
+		assert.True(t, tournament.Finished())
+
+		rounds := make(map[int][]pokemon.TournamentMatch)
+		met := make(map[[2]string]bool)
+		byes := make(map[string]int)
+		for _, m := range tournament.Matches {
+			rounds[m.Round] = append(rounds[m.Round], m)
+			if m.Bye() {
+				byes[m.PokemonA]++
+				continue
+			}
+			assert.False(t, met[[2]string{m.PokemonA, m.PokemonB}], "rematch %v", m)
+			met[[2]string{m.PokemonA, m.PokemonB}] = true
+			met[[2]string{m.PokemonB, m.PokemonA}] = true
+		}
+		assert.Len(t, rounds, pokemon.SwissRounds(n))
+		for round, matches := range rounds {
+			assert.Len(t, matches, (n+1)/2, "round %d", round)
+		}
+		for name, count := range byes {
+			assert.Equal(t, 1, count, name)
+		}
+
+		standings := tournament.Standings()
+		assert.Equal(t, "p1", standings[0].Pokemon)
+		assert.Equal(t, 3, standings[0].Points)
+		assert.Equal(t, 1, standings[0].Rank)
+		assert.Equal(t, 2, standings[1].Rank)
+	}
+}
+
+func TestSwissBuchholz(t *testing.T) {
+	tournament := pokemon.Tournament{
+		Format: pokemon.TournamentSwiss,
+		Rounds: 2,
+		Entrants: []pokemon.TournamentEntrant{
+			{Pokemon: "a", Seed: 1}, {Pokemon: "b", Seed: 2}, {Pokemon: "c", Seed: 3}, {Pokemon: "d", Seed: 4},
+		},
+		Matches: []pokemon.TournamentMatch{
+			{Round: 1, Position: 0, PokemonA: "a", PokemonB: "b", Winner: "a"},
+			{Round: 1, Position: 1, PokemonA: "c", PokemonB: "d", Winner: "c"},
+		},
+	}
+
+	next := tournament.NextRound()
+	assert.Equal(t, [][2]string{{"a", "c"}, {"b", "d"}}, [][2]string{{next[0].PokemonA, next[0].PokemonB}, {next[1].PokemonA, next[1].PokemonB}})
+
+	next[0].Winner, next[1].Winner = "c", "b"
+	tournament.Matches = append(tournament.Matches, next...)
+	assert.True(t, tournament.Finished())
+
+	// a and b both finish on one point, but a's opponents (b, c) scored more
+	// than b's (a, d)
+	standings := tournament.Standings()
+	testTable := []struct {
+		expectedPokemon  string
+		expectedRank     int
+		expectedPoints   int
+		expectedBuchholz int
+	}{
+		{expectedPokemon: "c", expectedRank: 1, expectedPoints: 2, expectedBuchholz: 1},
+		{expectedPokemon: "a", expectedRank: 2, expectedPoints: 1, expectedBuchholz: 3},
+		{expectedPokemon: "b", expectedRank: 3, expectedPoints: 1, expectedBuchholz: 1},
+		{expectedPokemon: "d", expectedRank: 4, expectedPoints: 0, expectedBuchholz: 3},
+	}
+	for i, test := range testTable {
+		assert.Equal(t, test.expectedPokemon, standings[i].Pokemon)
+		assert.Equal(t, test.expectedRank, standings[i].Rank, test.expectedPokemon)
+		assert.Equal(t, test.expectedPoints, standings[i].Points, test.expectedPokemon)
+		assert.Equal(t, test.expectedBuchholz, standings[i].Buchholz, test.expectedPokemon)
+	}
+}
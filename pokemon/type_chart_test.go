@@ -0,0 +1,86 @@
+package pokemon_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func TestTypeEffectiveness(t *testing.T) {
+	testTable := []struct {
+		attackType      string
+		defenderTypes   []string
+		expectedOutcome float64
+	}{
+		{attackType: "water", defenderTypes: []string{"fire"}, expectedOutcome: 2},
+		{attackType: "fire", defenderTypes: []string{"water"}, expectedOutcome: 0.5},
+		{attackType: "ice", defenderTypes: []string{"dragon", "flying"}, expectedOutcome: 4},
+		{attackType: "electric", defenderTypes: []string{"ground"}, expectedOutcome: 0},
+		{attackType: "grass", defenderTypes: []string{"water", "ground"}, expectedOutcome: 4},
+		{attackType: "fire", defenderTypes: []string{"water", "rock"}, expectedOutcome: 0.25},
+		{attackType: "normal", defenderTypes: []string{"psychic"}, expectedOutcome: 1},
+		{attackType: "normal", defenderTypes: nil, expectedOutcome: 1},
+	}
+
+	for _, test := range testTable {
+		result := pokemon.TypeEffectiveness(test.attackType, test.defenderTypes)
+		assert.Equal(t, test.expectedOutcome, result, "%s vs %v", test.attackType, test.defenderTypes)
+	}
+}
+
+func TestMatchupMultiplier(t *testing.T) {
+	assert.Equal(t, 2.0, pokemon.MatchupMultiplier([]string{"grass", "poison"}, []string{"water"}))
+	assert.Equal(t, 2.0, pokemon.MatchupMultiplier([]string{"grass", "poison"}, []string{"fairy"}))
+	assert.Equal(t, 1.0, pokemon.MatchupMultiplier(nil, []string{"water"}))
+	assert.Equal(t, 0.0, pokemon.MatchupMultiplier([]string{"normal"}, []string{"ghost"}))
+}
+
+func TestPokemonDetailDataSourceToPokemonTypes(t *testing.T) {
+	p := pokemon.New()
+	var pokeDataSource model.PokeDetailDataSourceRes
+	err := json.Unmarshal([]byte(`{
+		"name": "bulbasaur",
+		"stats": [{"base_stat": 45, "stat": {"name": "hp"}}],
+		"types": [{"slot": 1, "type": {"name": "grass"}}, {"slot": 2, "type": {"name": "poison"}}]
+	}`), &pokeDataSource)
+	assert.NoError(t, err)
+
+	result := p.PokemonDetailDataSourceToPokemon(pokeDataSource)
+	assert.Equal(t, []string{"grass", "poison"}, result.Types)
+}
+
+func TestFightPokemonByType(t *testing.T) {
+	p := pokemon.New()
+	pokemons := []model.Pokemon{
+		{Name: "charmander", Types: []string{"fire"}, CombatPower: 51.5},
+		{Name: "squirtle", Types: []string{"water"}, CombatPower: 52.33},
+		{Name: "bulbasaur", Types: []string{"grass", "poison"}, CombatPower: 53},
+	}
+
+	result, err := p.Fight(pokemon.FightModeType, pokemons, pokemon.DefaultTieBreakers, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "bulbasaur", result[0].Name)
+	assert.Equal(t, 79.5, result[0].EffectivePower)
+	assert.Equal(t, "squirtle", result[1].Name)
+	assert.Equal(t, 65.41, result[1].EffectivePower)
+	assert.Equal(t, "charmander", result[2].Name)
+	assert.Equal(t, 64.38, result[2].EffectivePower)
+
+	result, err = p.Fight(pokemon.FightModeCombatPower, []model.Pokemon{
+		{Name: "charmander", Types: []string{"fire"}, CombatPower: 60},
+		{Name: "squirtle", Types: []string{"water"}, CombatPower: 50},
+	}, pokemon.DefaultTieBreakers, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "charmander", result[0].Name)
+
+	result, err = p.Fight(pokemon.FightModeType, result, pokemon.DefaultTieBreakers, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "squirtle", result[0].Name)
+	assert.Equal(t, 100.0, result[0].EffectivePower)
+	assert.Equal(t, 30.0, result[1].EffectivePower)
+
+	_, err = p.Fight("coin-toss", pokemons, pokemon.DefaultTieBreakers, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pokeapi/entity"
+	"time"
+)
+
+type CacheRepository struct {
+	DB *gorm.DB
+}
+
+func NewCacheRepository(mysql *gorm.DB) CacheRepository {
+	return CacheRepository{
+		DB: mysql,
+	}
+}
+
+func (r CacheRepository) Get(key string) ([]byte, time.Time, bool, error) {
+	var cacheEntry entity.CacheEntry
+	err := r.DB.Where("`key` = ? AND expires_at > ?", key, time.Now()).First(&cacheEntry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, time.Time{}, false, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	return cacheEntry.Value, cacheEntry.ExpiresAt, true, nil
+}
+
+func (r CacheRepository) Set(key string, value []byte, expiresAt time.Time) error {
+	return r.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&entity.CacheEntry{
+		Key:       key,
+		Value:     value,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func (r CacheRepository) Delete(key string) (bool, error) {
+	res := r.DB.Where("`key` = ?", key).Delete(&entity.CacheEntry{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r CacheRepository) Purge() error {
+	return r.DB.Where("1 = 1").Delete(&entity.CacheEntry{}).Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pokeapi/entity"
+	"pokeapi/model"
+	"time"
+)
+
+type IdempotencyRepository struct {
+	DB *gorm.DB
+}
+
+func NewIdempotencyRepository(mysql *gorm.DB) IdempotencyRepository {
+	return IdempotencyRepository{
+		DB: mysql,
+	}
+}
+
+func (r IdempotencyRepository) Reserve(record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error) {
+	err := r.DB.Where("`key` = ? AND expires_at <= ?", record.Key, now).Delete(&entity.IdempotencyKey{}).Error
+	if err != nil {
+		return model.IdempotencyRecord{}, false, err
+	}
+
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.IdempotencyKey{
+		Key:         record.Key,
+		Fingerprint: record.Fingerprint,
+		ExpiresAt:   record.ExpiresAt,
+	})
+	if res.Error != nil {
+		return model.IdempotencyRecord{}, false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing entity.IdempotencyKey
+	err = r.DB.Where("`key` = ?", record.Key).First(&existing).Error
+	if err != nil {
+		return model.IdempotencyRecord{}, false, err
+	}
+
+	return model.IdempotencyRecord{
+		Key:         existing.Key,
+		Fingerprint: existing.Fingerprint,
+		StatusCode:  existing.StatusCode,
+		ContentType: existing.ContentType,
+		Body:        existing.ResponseBody,
+		ExpiresAt:   existing.ExpiresAt,
+	}, false, nil
+}
+
+func (r IdempotencyRepository) Complete(record model.IdempotencyRecord) error {
+	return r.DB.Model(&entity.IdempotencyKey{}).
+		Where("`key` = ?", record.Key).
+		Updates(map[string]any{
+			"status_code":   record.StatusCode,
+			"content_type":  record.ContentType,
+			"response_body": record.Body,
+			"expires_at":    record.ExpiresAt,
+		}).Error
+}
+
+func (r IdempotencyRepository) Release(key string) error {
+	return r.DB.Where("`key` = ?", key).Delete(&entity.IdempotencyKey{}).Error
+}
+
+func (r IdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	res := r.DB.Where("expires_at <= ?", now).Delete(&entity.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
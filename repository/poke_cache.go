@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"pokeapi/cache"
+	"pokeapi/model"
+	"sync/atomic"
+	"time"
+)
+
+// PokeCacheStore is the optional second cache tier that survives restarts.
+// Get also returns when the value expires. Delete reports whether the key was
+// stored.
+type PokeCacheStore interface {
+	Get(key string) ([]byte, time.Time, bool, error)
+	Set(key string, value []byte, expiresAt time.Time) error
+	Delete(key string) (bool, error)
+	Purge() error
+}
+
+// CachedPokeDataSource is a read-through cache in front of another
+// PokeDataSource. Lookups go memory, then Store (when set), then Source.
+// Both tiers hold the encoded JSON and decode it on every hit, so callers
+// get their own copy to change.
+type CachedPokeDataSource struct {
+	Source PokeDataSource
+	Memory *cache.LRU
+	Store  PokeCacheStore
+
+	memoryHits atomic.Int64
+	storeHits  atomic.Int64
+	misses     atomic.Int64
+}
+
+func NewCachedPokeDataSource(source PokeDataSource, memory *cache.LRU, store PokeCacheStore) *CachedPokeDataSource {
+	return &CachedPokeDataSource{
+		Source: source,
+		Memory: memory,
+		Store:  store,
+	}
+}
+
+func (d *CachedPokeDataSource) GetAllPokemon(offset int) (model.PokeDataSourceRes, error) {
+	return cached(d, fmt.Sprintf("pokemon?offset=%d", offset), func() (model.PokeDataSourceRes, error) {
+		return d.Source.GetAllPokemon(offset)
+	})
+}
+
+func (d *CachedPokeDataSource) GetOnePokemon(name string) (model.PokeDetailDataSourceRes, error) {
+	return cached(d, fmt.Sprintf("pokemon/%s", name), func() (model.PokeDetailDataSourceRes, error) {
+		return d.Source.GetOnePokemon(name)
+	})
+}
+
+func (d *CachedPokeDataSource) GetMove(name string) (model.PokeMoveDataSourceRes, error) {
+	return cached(d, fmt.Sprintf("move/%s", name), func() (model.PokeMoveDataSourceRes, error) {
+		return d.Source.GetMove(name)
+	})
+}
+
+func (d *CachedPokeDataSource) GetSpecies(name string) (model.PokeSpeciesDataSourceRes, error) {
+	return cached(d, fmt.Sprintf("pokemon-species/%s", name), func() (model.PokeSpeciesDataSourceRes, error) {
+		return d.Source.GetSpecies(name)
+	})
+}
+
+func (d *CachedPokeDataSource) GetEvolutionChain(id int) (model.PokeEvolutionChainDataSourceRes, error) {
+	return cached(d, fmt.Sprintf("evolution-chain/%d", id), func() (model.PokeEvolutionChainDataSourceRes, error) {
+		return d.Source.GetEvolutionChain(id)
+	})
+}
+
+// Delete drops the key from every tier and reports whether any tier held it.
+func (d *CachedPokeDataSource) Delete(key string) (bool, error) {
+	deleted := d.Memory.Delete(key)
+	if d.Store != nil {
+		stored, err := d.Store.Delete(key)
+		if err != nil {
+			return deleted, err
+		}
+		deleted = deleted || stored
+	}
+	return deleted, nil
+}
+
+func (d *CachedPokeDataSource) Purge() error {
+	d.Memory.Purge()
+	if d.Store != nil {
+		return d.Store.Purge()
+	}
+	return nil
+}
+
+func (d *CachedPokeDataSource) Stats() model.CacheStats {
+	stats := model.CacheStats{
+		Entries:    d.Memory.Len(),
+		Persistent: d.Store != nil,
+		MemoryHits: d.memoryHits.Load(),
+		StoreHits:  d.storeHits.Load(),
+		Misses:     d.misses.Load(),
+	}
+	if total := stats.MemoryHits + stats.StoreHits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.MemoryHits+stats.StoreHits) / float64(total)
+	}
+	return stats
+}
+
+func cached[T any](d *CachedPokeDataSource, key string, fetch func() (T, error)) (T, error) {
+	if raw, ok := d.Memory.Get(key); ok {
+		var value T
+		if json.Unmarshal(raw.([]byte), &value) == nil {
+			d.memoryHits.Add(1)
+			return value, nil
+		}
+	}
+
+	if d.Store != nil {
+		raw, expiresAt, ok, err := d.Store.Get(key)
+		if err == nil && ok {
+			var value T
+			if json.Unmarshal(raw, &value) == nil {
+				d.storeHits.Add(1)
+				d.Memory.SetUntil(key, raw, expiresAt)
+				return value, nil
+			}
+		}
+	}
+
+	d.misses.Add(1)
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	// A value that cannot be encoded is served uncached.
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value, nil
+	}
+	d.Memory.Set(key, raw)
+	if d.Store != nil {
+		// The persistent tier is best effort, a failed write only costs a refetch later.
+		_ = d.Store.Set(key, raw, d.expiresAt())
+	}
+
+	return value, nil
+}
+
+func (d *CachedPokeDataSource) expiresAt() time.Time {
+	if d.Memory.TTL() <= 0 {
+		return time.Now().AddDate(100, 0, 0)
+	}
+	return time.Now().Add(d.Memory.TTL())
+}
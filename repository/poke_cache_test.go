@@ -0,0 +1,139 @@
+package repository_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/cache"
+	"pokeapi/model"
+	"pokeapi/repository"
+	"testing"
+	"time"
+)
+
+type countingDataSource struct {
+	repository.PokeDataSource
+	calls int
+}
+
+func (d *countingDataSource) GetOnePokemon(name string) (model.PokeDetailDataSourceRes, error) {
+	d.calls++
+	return d.PokeDataSource.GetOnePokemon(name)
+}
+
+type storedEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+type memoryStore map[string]storedEntry
+
+func (s memoryStore) Get(key string) ([]byte, time.Time, bool, error) {
+	entry, ok := s[key]
+	return entry.value, entry.expiresAt, ok, nil
+}
+
+func (s memoryStore) Set(key string, value []byte, expiresAt time.Time) error {
+	s[key] = storedEntry{value: value, expiresAt: expiresAt}
+	return nil
+}
+
+func (s memoryStore) Delete(key string) (bool, error) {
+	_, ok := s[key]
+	delete(s, key)
+	return ok, nil
+}
+
+func (s memoryStore) Purge() error {
+	for key := range s {
+		delete(s, key)
+	}
+	return nil
+}
+
+func TestCachedPokeDataSource(t *testing.T) {
+	source := &countingDataSource{PokeDataSource: repository.NewFilePokeDataSource("testdata")}
+	store := memoryStore{}
+	d := repository.NewCachedPokeDataSource(source, cache.NewLRU(10, time.Hour), store)
+
+	for i := 0; i < 3; i++ {
+		detail, err := d.GetOnePokemon("pikachu")
+		assert.NoError(t, err)
+		assert.Equal(t, "pikachu", detail.Name)
+	}
+	assert.Equal(t, 1, source.calls)
+	assert.Contains(t, store, "pokemon/pikachu")
+
+	// a restart empties memory but the persistent tier still answers
+	restarted := repository.NewCachedPokeDataSource(source, cache.NewLRU(10, time.Hour), store)
+	_, err := restarted.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, source.calls)
+	assert.Equal(t, int64(1), restarted.Stats().StoreHits)
+
+	deleted, err := d.Delete("pokemon/pikachu")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	_, err = d.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, source.calls)
+
+	// an entry only in the persistent tier, e.g. cached by another instance,
+	// is deleted too
+	store["pokemon/pikachu"] = storedEntry{value: []byte("{}"), expiresAt: time.Now().Add(time.Hour)}
+	deleted, err = repository.NewCachedPokeDataSource(source, cache.NewLRU(10, time.Hour), store).Delete("pokemon/pikachu")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repository.NewCachedPokeDataSource(source, cache.NewLRU(10, time.Hour), store).Delete("pokemon/pikachu")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+
+	stats := d.Stats()
+	assert.Equal(t, int64(2), stats.MemoryHits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, 0.5, stats.HitRatio)
+
+	assert.NoError(t, d.Purge())
+	assert.Empty(t, store)
+	assert.Equal(t, 0, d.Memory.Len())
+}
+
+func TestCachedPokeDataSourceReturnsCopies(t *testing.T) {
+	source := &countingDataSource{PokeDataSource: repository.NewFilePokeDataSource("testdata")}
+	d := repository.NewCachedPokeDataSource(source, cache.NewLRU(10, time.Hour), nil)
+
+	detail, err := d.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+	stats := len(detail.Stats)
+	detail.Stats = detail.Stats[:0]
+	detail.Types[0].Type.Name = "ghost"
+
+	detail, err = d.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+	assert.Len(t, detail.Stats, stats)
+	assert.Equal(t, "electric", detail.Types[0].Type.Name)
+	assert.Equal(t, 1, source.calls)
+}
+
+func TestCachedPokeDataSourceKeepsStoreExpiry(t *testing.T) {
+	source := &countingDataSource{PokeDataSource: repository.NewFilePokeDataSource("testdata")}
+	store := memoryStore{}
+	d := repository.NewCachedPokeDataSource(source, cache.NewLRU(10, time.Hour), store)
+	_, err := d.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+
+	// the stored entry is about to expire, so memory must not keep it for
+	// another full hour
+	entry := store["pokemon/pikachu"]
+	entry.expiresAt = time.Now().Add(20 * time.Millisecond)
+	store["pokemon/pikachu"] = entry
+
+	restarted := repository.NewCachedPokeDataSource(source, cache.NewLRU(10, time.Hour), store)
+	_, err = restarted.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, source.calls)
+
+	time.Sleep(30 * time.Millisecond)
+	delete(store, "pokemon/pikachu")
+	_, err = restarted.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, source.calls)
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"pokeapi/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPokeApiBaseURL = "https://pokeapi.co/api/v2"
+	DefaultPokeApiTimeout = 10 * time.Second
+	listPokemonLimit      = 10
+)
+
+var (
+	ErrNotFound = errors.New("resource not found")
+	ErrConflict = errors.New("resource state conflict")
+)
+
+// PokeDataSource is where Pokémon data is read from. PokeApiDataSource talks to
+// PokeAPI over HTTP, FilePokeDataSource reads the same JSON documents from disk.
+type PokeDataSource interface {
+	GetAllPokemon(offset int) (model.PokeDataSourceRes, error)
+	GetOnePokemon(name string) (model.PokeDetailDataSourceRes, error)
+	GetMove(name string) (model.PokeMoveDataSourceRes, error)
+	GetSpecies(name string) (model.PokeSpeciesDataSourceRes, error)
+	GetEvolutionChain(id int) (model.PokeEvolutionChainDataSourceRes, error)
+}
+
+type PokeApiDataSource struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewPokeApiDataSource(baseURL string, timeout time.Duration, client *http.Client) PokeApiDataSource {
+	if baseURL == "" {
+		baseURL = DefaultPokeApiBaseURL
+	}
+	if client == nil {
+		if timeout <= 0 {
+			timeout = DefaultPokeApiTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+	return PokeApiDataSource{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Client:  client,
+	}
+}
+
+func (d PokeApiDataSource) GetAllPokemon(offset int) (model.PokeDataSourceRes, error) {
+	var pokeApiRes model.PokeDataSourceRes
+	err := d.get(fmt.Sprintf("pokemon?limit=%d&offset=%d", listPokemonLimit, offset), &pokeApiRes)
+	if err != nil {
+		return model.PokeDataSourceRes{}, err
+	}
+	return pokeApiRes, nil
+}
+
+func (d PokeApiDataSource) GetOnePokemon(name string) (model.PokeDetailDataSourceRes, error) {
+	var pokeApi model.PokeDetailDataSourceRes
+	err := d.get(fmt.Sprintf("pokemon/%s", name), &pokeApi)
+	if err != nil {
+		return model.PokeDetailDataSourceRes{}, err
+	}
+	return pokeApi, nil
+}
+
+func (d PokeApiDataSource) GetMove(name string) (model.PokeMoveDataSourceRes, error) {
+	var pokeApi model.PokeMoveDataSourceRes
+	err := d.get(fmt.Sprintf("move/%s", name), &pokeApi)
+	if err != nil {
+		return model.PokeMoveDataSourceRes{}, err
+	}
+	return pokeApi, nil
+}
+
+func (d PokeApiDataSource) GetSpecies(name string) (model.PokeSpeciesDataSourceRes, error) {
+	var pokeApi model.PokeSpeciesDataSourceRes
+	err := d.get(fmt.Sprintf("pokemon-species/%s", name), &pokeApi)
+	if err != nil {
+		return model.PokeSpeciesDataSourceRes{}, err
+	}
+	return pokeApi, nil
+}
+
+func (d PokeApiDataSource) GetEvolutionChain(id int) (model.PokeEvolutionChainDataSourceRes, error) {
+	var pokeApi model.PokeEvolutionChainDataSourceRes
+	err := d.get(fmt.Sprintf("evolution-chain/%d", id), &pokeApi)
+	if err != nil {
+		return model.PokeEvolutionChainDataSourceRes{}, err
+	}
+	return pokeApi, nil
+}
+
+func (d PokeApiDataSource) get(path string, v any) error {
+	response, err := d.Client.Get(fmt.Sprintf("%s/%s", d.BaseURL, path))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", path, ErrNotFound)
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d from pokeapi", path, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+// FilePokeDataSource mirrors the PokeAPI URL layout on disk, e.g.
+// <Dir>/pokemon.json for the list, <Dir>/pokemon/pikachu.json for a detail and
+// <Dir>/move/thunderbolt.json for a move, <Dir>/pokemon-species/pikachu.json
+// for a species and <Dir>/evolution-chain/10.json for an evolution chain.
+type FilePokeDataSource struct {
+	Dir string
+}
+
+func NewFilePokeDataSource(dir string) FilePokeDataSource {
+	return FilePokeDataSource{
+		Dir: dir,
+	}
+}
+
+func (d FilePokeDataSource) GetAllPokemon(offset int) (model.PokeDataSourceRes, error) {
+	var pokeApiRes model.PokeDataSourceRes
+	err := d.read(&pokeApiRes, "pokemon")
+	if err != nil {
+		return model.PokeDataSourceRes{}, err
+	}
+
+	if pokeApiRes.Count == 0 {
+		pokeApiRes.Count = len(pokeApiRes.Results)
+	}
+	if offset < 0 || offset > len(pokeApiRes.Results) {
+		offset = len(pokeApiRes.Results)
+	}
+	end := offset + listPokemonLimit
+	if end > len(pokeApiRes.Results) {
+		end = len(pokeApiRes.Results)
+	}
+	pokeApiRes.Results = pokeApiRes.Results[offset:end]
+
+	return pokeApiRes, nil
+}
+
+func (d FilePokeDataSource) GetOnePokemon(name string) (model.PokeDetailDataSourceRes, error) {
+	var pokeApi model.PokeDetailDataSourceRes
+	err := d.read(&pokeApi, "pokemon", name)
+	if err != nil {
+		return model.PokeDetailDataSourceRes{}, err
+	}
+	return pokeApi, nil
+}
+
+func (d FilePokeDataSource) GetMove(name string) (model.PokeMoveDataSourceRes, error) {
+	var pokeApi model.PokeMoveDataSourceRes
+	err := d.read(&pokeApi, "move", name)
+	if err != nil {
+		return model.PokeMoveDataSourceRes{}, err
+	}
+	return pokeApi, nil
+}
+
+func (d FilePokeDataSource) GetSpecies(name string) (model.PokeSpeciesDataSourceRes, error) {
+	var pokeApi model.PokeSpeciesDataSourceRes
+	err := d.read(&pokeApi, "pokemon-species", name)
+	if err != nil {
+		return model.PokeSpeciesDataSourceRes{}, err
+	}
+	return pokeApi, nil
+}
+
+func (d FilePokeDataSource) GetEvolutionChain(id int) (model.PokeEvolutionChainDataSourceRes, error) {
+	var pokeApi model.PokeEvolutionChainDataSourceRes
+	err := d.read(&pokeApi, "evolution-chain", strconv.Itoa(id))
+	if err != nil {
+		return model.PokeEvolutionChainDataSourceRes{}, err
+	}
+	return pokeApi, nil
+}
+
+func (d FilePokeDataSource) read(v any, elem ...string) error {
+	path := strings.Join(elem, "/")
+	for _, e := range elem {
+		if e == "" || e == "." || e == ".." || strings.ContainsAny(e, `/\`) {
+			return fmt.Errorf("%s: %w", path, ErrNotFound)
+		}
+	}
+
+	file, err := os.Open(filepath.Join(d.Dir, filepath.Join(elem...)+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s: %w", path, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewDecoder(file).Decode(v)
+}
@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"pokeapi/repository"
+	"testing"
+	"time"
+)
+
+func TestFilePokeDataSource(t *testing.T) {
+	d := repository.NewFilePokeDataSource("testdata")
+
+	list, err := d.GetAllPokemon(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, list.Count)
+	assert.Equal(t, "bulbasaur", list.Results[0].Name)
+
+	list, err = d.GetAllPokemon(20)
+	assert.NoError(t, err)
+	assert.Empty(t, list.Results)
+
+	detail, err := d.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+	assert.Equal(t, "pikachu", detail.Name)
+	assert.Len(t, detail.Stats, 6)
+	assert.Len(t, detail.Abilities, 2)
+	assert.NotEmpty(t, detail.Moves)
+
+	_, err = d.GetOnePokemon("missingno")
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+
+	_, err = d.GetOnePokemon("../pokemon")
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+
+	move, err := d.GetMove("thunderbolt")
+	assert.NoError(t, err)
+	assert.Equal(t, 90, *move.Power)
+	assert.Equal(t, "electric", move.Type.Name)
+	assert.Equal(t, "special", move.DamageClass.Name)
+
+	move, err = d.GetMove("growl")
+	assert.NoError(t, err)
+	assert.Nil(t, move.Power)
+
+	_, err = d.GetMove("hyper-beem")
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+
+	species, err := d.GetSpecies("pikachu")
+	assert.NoError(t, err)
+	assert.Equal(t, 25, species.ID)
+	assert.Equal(t, "pichu", species.EvolvesFromSpecies.Name)
+	assert.Equal(t, "https://pokeapi.co/api/v2/evolution-chain/10/", species.EvolutionChain.URL)
+
+	_, err = d.GetSpecies("missingno")
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+
+	chain, err := d.GetEvolutionChain(10)
+	assert.NoError(t, err)
+	assert.Equal(t, "pichu", chain.Chain.Species.Name)
+	assert.Equal(t, "pikachu", chain.Chain.EvolvesTo[0].Species.Name)
+	assert.Equal(t, 220, *chain.Chain.EvolvesTo[0].EvolutionDetails[0].MinHappiness)
+
+	_, err = d.GetEvolutionChain(999)
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+}
+
+func TestPokeApiDataSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pokemon":
+			assert.Equal(t, "10", r.URL.Query().Get("limit"))
+			assert.Equal(t, "10", r.URL.Query().Get("offset"))
+			http.ServeFile(w, r, "testdata/pokemon.json")
+		case "/pokemon/pikachu":
+			body, _ := os.ReadFile("testdata/pokemon/pikachu.json")
+			w.Write(body)
+		case "/move/thunderbolt":
+			http.ServeFile(w, r, "testdata/move/thunderbolt.json")
+		case "/pokemon-species/pikachu":
+			http.ServeFile(w, r, "testdata/pokemon-species/pikachu.json")
+		case "/evolution-chain/10":
+			http.ServeFile(w, r, "testdata/evolution-chain/10.json")
+		case "/pokemon/slowpoke":
+			time.Sleep(200 * time.Millisecond)
+		case "/pokemon/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	d := repository.NewPokeApiDataSource(server.URL+"/", 50*time.Millisecond, nil)
+
+	list, err := d.GetAllPokemon(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, list.Count)
+
+	detail, err := d.GetOnePokemon("pikachu")
+	assert.NoError(t, err)
+	assert.Equal(t, "pikachu", detail.Name)
+
+	_, err = d.GetOnePokemon("missingno")
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+
+	move, err := d.GetMove("thunderbolt")
+	assert.NoError(t, err)
+	assert.Equal(t, "thunderbolt", move.Name)
+
+	_, err = d.GetMove("hyper-beem")
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+
+	species, err := d.GetSpecies("pikachu")
+	assert.NoError(t, err)
+	assert.Equal(t, "pikachu", species.Name)
+
+	chain, err := d.GetEvolutionChain(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, chain.ID)
+
+	_, err = d.GetEvolutionChain(11)
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+
+	_, err = d.GetOnePokemon("broken")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, repository.ErrNotFound))
+
+	_, err = d.GetOnePokemon("slowpoke")
+	assert.Error(t, err)
+}
@@ -1,13 +1,14 @@
 package repository
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
-	"net/http"
+	"gorm.io/gorm/clause"
 	"pokeapi/entity"
 	"pokeapi/model"
+	"pokeapi/pokemon"
+	"sort"
 	"time"
 )
 
@@ -21,116 +22,622 @@ func NewPokeRepository(mysql *gorm.DB) PokeRepository {
 	}
 }
 
-func (r PokeRepository) GetAllPokemon(offset int) (model.PokeDataSourceRes, error) {
-	url := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon?limit=10&offset=%d", offset)
-
-	response, err := http.Get(url)
-	if err != nil {
-		return model.PokeDataSourceRes{}, err
-	}
-	defer response.Body.Close()
-
-	var pokeApiRes model.PokeDataSourceRes
-	err = json.NewDecoder(response.Body).Decode(&pokeApiRes)
+// InsertFight stores a fight history together with its details and battle
+// turns in one transaction, so a failed insert never leaves an orphan history.
+func (r PokeRepository) InsertFight(fightHistory entity.FightHistory) (entity.FightHistory, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		fightHistory, err = insertFight(tx, fightHistory)
+		return err
+	})
 	if err != nil {
-		return model.PokeDataSourceRes{}, err
+		return entity.FightHistory{}, err
 	}
 
-	return pokeApiRes, nil
+	return fightHistory, nil
 }
 
-func (r PokeRepository) GetOnePokemon(name string) (model.PokeDetailDataSourceRes, error) {
-	url := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s", name)
-
-	response, err := http.Get(url)
-	if err != nil {
-		return model.PokeDetailDataSourceRes{}, err
+func insertFight(tx *gorm.DB, fightHistory entity.FightHistory) (entity.FightHistory, error) {
+	if fightHistory.TrainerID != nil {
+		var trainer entity.Trainer
+		err := tx.Select("id").First(&trainer, *fightHistory.TrainerID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.FightHistory{}, fmt.Errorf("trainer %d: %w", *fightHistory.TrainerID, ErrNotFound)
+		}
+		if err != nil {
+			return entity.FightHistory{}, err
+		}
 	}
-	defer response.Body.Close()
 
-	var pokeApi model.PokeDetailDataSourceRes
-	err = json.NewDecoder(response.Body).Decode(&pokeApi)
-	if err != nil {
-		return model.PokeDetailDataSourceRes{}, err
+	var teamTrainers []uint
+	for _, team := range fightHistory.Teams {
+		if team.TrainerID != nil {
+			teamTrainers = append(teamTrainers, *team.TrainerID)
+		}
+	}
+	if len(teamTrainers) > 0 {
+		var count int64
+		err := tx.Model(&entity.Trainer{}).Where("id IN ?", teamTrainers).Count(&count).Error
+		if err != nil {
+			return entity.FightHistory{}, err
+		}
+		if count < int64(len(teamTrainers)) {
+			return entity.FightHistory{}, fmt.Errorf("trainers %v: %w", teamTrainers, ErrNotFound)
+		}
 	}
 
-	return pokeApi, nil
-}
+	// the season may have been closed, or reached its end date, since the
+	// fight was assigned to it
+	if fightHistory.SeasonID != nil {
+		var season entity.Season
+		err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+			Select("id").
+			Where("closed_at IS NULL AND (ends_at IS NULL OR ends_at > ?)", time.Now()).
+			First(&season, *fightHistory.SeasonID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.FightHistory{}, fmt.Errorf("season %d is closed or has ended: %w", *fightHistory.SeasonID, ErrConflict)
+		}
+		if err != nil {
+			return entity.FightHistory{}, err
+		}
+	}
 
-func (r PokeRepository) InsertFightHistory() (entity.FightHistory, error) {
-	var fightHistory entity.FightHistory
-	res := r.DB.Create(&fightHistory)
+	res := tx.Omit(clause.Associations).Create(&fightHistory)
+	if res.Error != nil {
+		return entity.FightHistory{}, res.Error
+	}
 	if res.RowsAffected == 0 {
 		return entity.FightHistory{}, errors.New("failed insert fight history data")
 	}
-	return fightHistory, nil
-}
 
-func (r PokeRepository) InsertFightHistoryDetail(fightHistoryDetail []entity.FightHistoryDetail) ([]entity.FightHistoryDetail, error) {
-	res := r.DB.CreateInBatches(&fightHistoryDetail, len(fightHistoryDetail))
-	if res.RowsAffected < int64(len(fightHistoryDetail)) {
-		return []entity.FightHistoryDetail{}, errors.New("failed insert fight history detail data in batch")
+	for i := range fightHistory.FightHistoryDetail {
+		fightHistory.FightHistoryDetail[i].FightHistoryID = fightHistory.ID
 	}
-	return fightHistoryDetail, nil
+	if len(fightHistory.FightHistoryDetail) > 0 {
+		res = tx.CreateInBatches(&fightHistory.FightHistoryDetail, len(fightHistory.FightHistoryDetail))
+		if res.Error != nil {
+			return entity.FightHistory{}, res.Error
+		}
+		if res.RowsAffected < int64(len(fightHistory.FightHistoryDetail)) {
+			return entity.FightHistory{}, errors.New("failed insert fight history detail data in batch")
+		}
+	}
+
+	// team members share their team's rank, so team fights are not rated
+	if fightHistory.Mode != pokemon.FightModeTeam {
+		err := applyFightRatings(tx, fightHistory.ID, fightHistory.FightHistoryDetail)
+		if err != nil {
+			return entity.FightHistory{}, err
+		}
+	}
+
+	for i := range fightHistory.BattleTurns {
+		fightHistory.BattleTurns[i].FightHistoryID = fightHistory.ID
+	}
+	if len(fightHistory.BattleTurns) > 0 {
+		res = tx.CreateInBatches(&fightHistory.BattleTurns, 100)
+		if res.Error != nil {
+			return entity.FightHistory{}, res.Error
+		}
+		if res.RowsAffected < int64(len(fightHistory.BattleTurns)) {
+			return entity.FightHistory{}, errors.New("failed insert battle turn data in batch")
+		}
+	}
+
+	for i := range fightHistory.Teams {
+		fightHistory.Teams[i].FightHistoryID = fightHistory.ID
+	}
+	if len(fightHistory.Teams) > 0 {
+		res = tx.Create(&fightHistory.Teams)
+		if res.Error != nil {
+			return entity.FightHistory{}, res.Error
+		}
+	}
+
+	return fightHistory, nil
 }
 
-func (r PokeRepository) GetFightHistory(req model.PokemonReqQuery) ([]entity.FightHistory, error) {
+// GetFightHistory returns one page of at most req.Limit fights after
+// req.Cursor, together with the number of fights matching the filters.
+func (r PokeRepository) GetFightHistory(req model.PokemonReqQuery) ([]entity.FightHistory, int64, error) {
 	var fightHistories []entity.FightHistory
-	db := r.DB.Preload("FightHistoryDetail")
-
 	if req.StartDate != "" && req.EndDate != "" {
 		_, err := time.Parse("2006-01-02 15:04:05", req.StartDate)
 		if err != nil {
-			return []entity.FightHistory{}, err
+			return []entity.FightHistory{}, 0, err
 		}
 
 		_, err = time.Parse("2006-01-02 15:04:05", req.EndDate)
 		if err != nil {
-			return []entity.FightHistory{}, err
+			return []entity.FightHistory{}, 0, err
+		}
+	}
+
+	var total int64
+	err := filterFightHistory(r.DB.Model(&entity.FightHistory{}), req).Count(&total).Error
+	if err != nil {
+		return []entity.FightHistory{}, 0, err
+	}
+
+	db := filterFightHistory(r.DB, req).Preload("FightHistoryDetail").Preload("BattleTurns", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Teams.Members")
+	if req.Sort == model.HistorySortOldest {
+		if req.Cursor != 0 {
+			db = db.Where("fight_histories.id > ?", req.Cursor)
+		}
+		db = db.Order("fight_histories.id ASC")
+	} else {
+		if req.Cursor != 0 {
+			db = db.Where("fight_histories.id < ?", req.Cursor)
 		}
+		db = db.Order("fight_histories.id DESC")
+	}
+	if req.Limit > 0 {
+		db = db.Limit(req.Limit)
+	}
 
-		db = db.Where("created_at BETWEEN ? AND ?", req.StartDate, req.EndDate)
+	err = db.Find(&fightHistories).Error
+	if err != nil {
+		return []entity.FightHistory{}, 0, err
 	}
 
-	err := db.Order("id DESC").Find(&fightHistories).Error
+	return fightHistories, total, nil
+}
+
+func (r PokeRepository) GetFightHistoryByID(id uint) (entity.FightHistory, error) {
+	var fightHistory entity.FightHistory
+	err := r.DB.Preload("FightHistoryDetail").Preload("BattleTurns", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Teams", func(db *gorm.DB) *gorm.DB {
+		return db.Order("team")
+	}).Preload("Teams.Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("slot")
+	}).First(&fightHistory, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.FightHistory{}, fmt.Errorf("fight history %d: %w", id, ErrNotFound)
+	}
 	if err != nil {
-		return []entity.FightHistory{}, err
+		return entity.FightHistory{}, err
 	}
+	return fightHistory, nil
+}
 
-	return fightHistories, nil
+// GetActiveSeason returns the open season that has started and not yet
+// reached its end date, or ErrNotFound when fights are not in a season.
+func (r PokeRepository) GetActiveSeason(now time.Time) (entity.Season, error) {
+	var season entity.Season
+	err := r.DB.Where("closed_at IS NULL AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Order("id DESC").
+		First(&season).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Season{}, fmt.Errorf("active season: %w", ErrNotFound)
+	}
+	if err != nil {
+		return entity.Season{}, err
+	}
+	return season, nil
+}
+
+func (r PokeRepository) GetSumScore(req model.LeaderboardReqQuery) ([]model.Leaderboard, error) {
+	return sumScore(r.DB, req)
 }
 
-func (r PokeRepository) GetSumScore() ([]model.Leaderboard, error) {
+func sumScore(db *gorm.DB, req model.LeaderboardReqQuery) ([]model.Leaderboard, error) {
 	var leaderboard []model.Leaderboard
-	_ = r.DB.Table("fight_history_details").
-		Select("pokemon, SUM(score) as total_score").
-		Group("pokemon").
+	db = db.Table("fight_history_details").
+		Select("fight_history_details.pokemon, " +
+			"SUM(fight_history_details.score) as total_score, " +
+			"SUM(CASE WHEN fight_history_details.cancelled THEN 0 ELSE 1 END) as fights, " +
+			"SUM(CASE WHEN fight_history_details.`rank` = 1 AND NOT fight_history_details.cancelled THEN 1 ELSE 0 END) as wins").
+		Joins("JOIN fight_histories ON fight_histories.id = fight_history_details.fight_history_id")
+
+	if req.SeasonID != nil {
+		db = db.Where("fight_histories.season_id = ?", *req.SeasonID)
+	}
+	if req.StartDate != "" {
+		db = db.Where("fight_histories.created_at >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		db = db.Where("fight_histories.created_at <= ?", req.EndDate)
+	}
+
+	err := db.Group("fight_history_details.pokemon").
 		Order("total_score DESC").
-		Scan(&leaderboard)
+		Scan(&leaderboard).Error
+	if err != nil {
+		return []model.Leaderboard{}, err
+	}
 
 	return leaderboard, nil
 }
 
+const (
+	AuditActionCancel = "cancel"
+	AuditActionRevert = "revert"
+)
+
 func (r PokeRepository) CancelScorePokemon(req model.PokemonCancelReqBody) (entity.FightHistoryDetail, error) {
-	var fightHistoryDetail entity.FightHistoryDetail
-	err := r.DB.Where("fight_history_id = ? AND pokemon = ? AND score != ?", req.FightHistoryID, req.Pokemon, 0).
-		First(&fightHistoryDetail).Error
+	var cancelledDetail entity.FightHistoryDetail
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		fightHistory, err := lockFightHistory(tx, uint(req.FightHistoryID))
+		if err != nil {
+			return err
+		}
+
+		if fightHistory.Mode == pokemon.FightModeTeam {
+			return fmt.Errorf("fight history %d is a team fight, its members cannot be cancelled: %w", req.FightHistoryID, ErrConflict)
+		}
+
+		scoringRule, err := pokemon.NewScoringRule(fightHistory.ScoringRule, nil)
+		if err != nil {
+			return err
+		}
+
+		before := fightHistory.FightHistoryDetail
+		details := rankFightHistoryDetails(before)
+		cancelled := -1
+		for i, d := range details {
+			if d.Pokemon == req.Pokemon && !d.Cancelled {
+				cancelled = i
+			}
+		}
+		if cancelled == -1 {
+			return fmt.Errorf("%s in fight history %d: %w", req.Pokemon, req.FightHistoryID, ErrNotFound)
+		}
+
+		cancelledRank := details[cancelled].Rank
+		details[cancelled].Cancelled = true
+		details[cancelled].Rank = 0
+		for i := range details {
+			if !details[i].Cancelled && details[i].Rank > cancelledRank {
+				details[i].Rank--
+			}
+		}
+		scoreFightHistoryDetails(details, scoringRule)
+
+		err = tx.Save(&details).Error
+		if err != nil {
+			return err
+		}
+
+		cancelledDetail = details[cancelled]
+		audit := entity.CancellationAudit{
+			FightHistoryID: fightHistory.ID,
+			Action:         AuditActionCancel,
+			Pokemon:        req.Pokemon,
+			Actor:          req.Actor,
+			Reason:         req.Reason,
+			Changes:        auditChanges(before, details),
+		}
+		err = tx.Create(&audit).Error
+		if err != nil {
+			return err
+		}
+
+		return rerateFrom(tx, fightHistory.ID)
+	})
 	if err != nil {
 		return entity.FightHistoryDetail{}, err
 	}
 
-	err = r.DB.Model(&entity.FightHistoryDetail{}).
-		Where("fight_history_id = ? AND score < ? AND score > ?", req.FightHistoryID, fightHistoryDetail.Score, 0).
-		Update("score", gorm.Expr("score + ?", 1)).Error
+	return cancelledDetail, nil
+}
+
+// RevertCancellation restores the scores recorded before a cancellation. It
+// only applies while the fight still looks exactly like the cancellation left
+// it; later cancellations in the same fight have to be reverted first.
+func (r PokeRepository) RevertCancellation(auditID uint, req model.CancelRevertReqBody) (entity.CancellationAudit, error) {
+	var revert entity.CancellationAudit
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var audit entity.CancellationAudit
+		err := tx.Preload("Changes").Where("action = ?", AuditActionCancel).First(&audit, auditID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("cancellation %d: %w", auditID, ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+
+		fightHistory, err := lockFightHistory(tx, audit.FightHistoryID)
+		if err != nil {
+			return err
+		}
+
+		var reverted int64
+		err = tx.Model(&entity.CancellationAudit{}).Where("reverts_id = ?", audit.ID).Count(&reverted).Error
+		if err != nil {
+			return err
+		}
+		if reverted > 0 {
+			return fmt.Errorf("cancellation %d is already reverted: %w", audit.ID, ErrConflict)
+		}
+
+		before := fightHistory.FightHistoryDetail
+		details := append([]entity.FightHistoryDetail(nil), before...)
+		changes := make(map[uint]entity.CancellationAuditChange)
+		for _, c := range audit.Changes {
+			changes[c.FightHistoryDetailID] = c
+		}
+		for i, d := range details {
+			c, ok := changes[d.ID]
+			if !ok {
+				continue
+			}
+			if d.Rank != c.RankAfter || d.Score != c.ScoreAfter || d.Cancelled != c.CancelledAfter {
+				return fmt.Errorf("fight history %d changed after cancellation %d: %w", fightHistory.ID, audit.ID, ErrConflict)
+			}
+			details[i].Rank = c.RankBefore
+			details[i].Score = c.ScoreBefore
+			details[i].Cancelled = c.CancelledBefore
+		}
+
+		err = tx.Save(&details).Error
+		if err != nil {
+			return err
+		}
+
+		revert = entity.CancellationAudit{
+			FightHistoryID: fightHistory.ID,
+			Action:         AuditActionRevert,
+			Pokemon:        audit.Pokemon,
+			Actor:          req.Actor,
+			Reason:         req.Reason,
+			RevertsID:      &audit.ID,
+			Changes:        auditChanges(before, details),
+		}
+		err = tx.Create(&revert).Error
+		if err != nil {
+			return err
+		}
+
+		return rerateFrom(tx, fightHistory.ID)
+	})
+	if err != nil {
+		return entity.CancellationAudit{}, err
+	}
+
+	return revert, nil
+}
+
+func (r PokeRepository) GetCancellationAudits(fightHistoryID uint) ([]entity.CancellationAudit, error) {
+	var fightHistory entity.FightHistory
+	err := r.DB.Select("id").First(&fightHistory, fightHistoryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []entity.CancellationAudit{}, fmt.Errorf("fight history %d: %w", fightHistoryID, ErrNotFound)
+	}
 	if err != nil {
-		return entity.FightHistoryDetail{}, err
+		return []entity.CancellationAudit{}, err
 	}
 
-	fightHistoryDetail.Score = 0
-	err = r.DB.Save(&fightHistoryDetail).Error
+	var audits []entity.CancellationAudit
+	err = r.DB.Preload("Changes").
+		Where("fight_history_id = ?", fightHistoryID).
+		Order("id").
+		Find(&audits).Error
 	if err != nil {
-		return entity.FightHistoryDetail{}, err
+		return []entity.CancellationAudit{}, err
+	}
+
+	return audits, nil
+}
+
+func auditChanges(before []entity.FightHistoryDetail, after []entity.FightHistoryDetail) []entity.CancellationAuditChange {
+	previous := make(map[uint]entity.FightHistoryDetail)
+	for _, d := range before {
+		previous[d.ID] = d
+	}
+
+	var changes []entity.CancellationAuditChange
+	for _, d := range after {
+		p := previous[d.ID]
+		changes = append(changes, entity.CancellationAuditChange{
+			FightHistoryDetailID: d.ID,
+			Pokemon:              d.Pokemon,
+			RankBefore:           p.Rank,
+			RankAfter:            d.Rank,
+			ScoreBefore:          p.Score,
+			ScoreAfter:           d.Score,
+			CancelledBefore:      p.Cancelled,
+			CancelledAfter:       d.Cancelled,
+		})
+	}
+	return changes
+}
+
+// RescoreFightHistories rescores every fight in one transaction, so a
+// failure leaves all scores as they were.
+func (r PokeRepository) RescoreFightHistories(scoringRule func(entity.FightHistory) (pokemon.ScoringRule, error)) (int, error) {
+	var rescored int
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Model(&entity.FightHistory{}).Order("id").Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			fightHistory, err := lockFightHistory(tx, id)
+			if err != nil {
+				return err
+			}
+
+			rule, err := scoringRule(fightHistory)
+			if err != nil {
+				return err
+			}
+
+			details := rankFightHistoryDetails(fightHistory.FightHistoryDetail)
+			scoreFightHistoryDetails(details, rule)
+			if len(details) > 0 {
+				if err := tx.Save(&details).Error; err != nil {
+					return err
+				}
+			}
+			if fightHistory.Mode == pokemon.FightModeTeam {
+				if err := rescoreFightTeams(tx, fightHistory.ID, rule); err != nil {
+					return err
+				}
+			}
+			err = tx.Model(&fightHistory).Update("scoring_rule", rule.Name()).Error
+			if err != nil {
+				return err
+			}
+			rescored++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return rescored, nil
+}
+
+// lockFightHistory loads a fight history and its details with SELECT ... FOR
+// UPDATE, so two cancellations in the same fight run one after the other.
+func lockFightHistory(tx *gorm.DB, id uint) (entity.FightHistory, error) {
+	var fightHistory entity.FightHistory
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&fightHistory, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.FightHistory{}, fmt.Errorf("fight history %d: %w", id, ErrNotFound)
 	}
+	if err != nil {
+		return entity.FightHistory{}, err
+	}
+
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("fight_history_id = ?", id).
+		Order("id").
+		Find(&fightHistory.FightHistoryDetail).Error
+	if err != nil {
+		return entity.FightHistory{}, err
+	}
+
+	return fightHistory, nil
+}
+
+// rankFightHistoryDetails fills in ranks for fights recorded before ranks were
+// stored: details were inserted in placement order and a cancelled one was
+// left with a score of 0.
+func rankFightHistoryDetails(details []entity.FightHistoryDetail) []entity.FightHistoryDetail {
+	details = append([]entity.FightHistoryDetail(nil), details...)
+	sort.Slice(details, func(i, j int) bool {
+		return details[i].ID < details[j].ID
+	})
+
+	for _, d := range details {
+		if d.Rank != 0 || d.Cancelled {
+			return details
+		}
+	}
+
+	rank := 1
+	for i := range details {
+		if details[i].Score == 0 {
+			details[i].Cancelled = true
+			continue
+		}
+		details[i].Rank = rank
+		rank++
+	}
+	return details
+}
+
+// scoreFightHistoryDetails scores every participant by rank, splitting the
+// points of shared ranks. In a team fight the teams are the participants and
+// every member gets the team's score.
+func scoreFightHistoryDetails(details []entity.FightHistoryDetail, scoringRule pokemon.ScoringRule) {
+	participants := make(map[int]int)
+	shared := make(map[int]int)
+	for i, d := range details {
+		participant := -1 - i
+		if d.Team != nil {
+			participant = *d.Team
+		}
+		if _, ok := participants[participant]; ok {
+			continue
+		}
+		participants[participant] = d.Rank
+		if !d.Cancelled {
+			shared[d.Rank]++
+		}
+	}
+
+	for i := range details {
+		if details[i].Cancelled {
+			details[i].Score = 0
+			continue
+		}
+		details[i].Score = pokemon.SharedScore(scoringRule, details[i].Rank, shared[details[i].Rank], len(participants))
+	}
+}
+
+func rescoreFightTeams(tx *gorm.DB, fightHistoryID uint, scoringRule pokemon.ScoringRule) error {
+	var teams []entity.FightTeam
+	err := tx.Where("fight_history_id = ?", fightHistoryID).Find(&teams).Error
+	if err != nil {
+		return err
+	}
+
+	shared := make(map[int]int)
+	for _, team := range teams {
+		shared[team.Rank]++
+	}
+	for _, team := range teams {
+		err = tx.Model(&team).Update("score", pokemon.SharedScore(scoringRule, team.Rank, shared[team.Rank], len(teams))).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterFightHistory applies the history filters. Pokémon name, minimum score
+// and cancellation status all have to hold for the same participant; without
+// a name, cancelled=true keeps fights with any cancelled participant and
+// cancelled=false those without one.
+func filterFightHistory(db *gorm.DB, req model.PokemonReqQuery) *gorm.DB {
+	if req.StartDate != "" && req.EndDate != "" {
+		db = db.Where("fight_histories.created_at BETWEEN ? AND ?", req.StartDate, req.EndDate)
+	}
+
+	if req.Participants > 0 {
+		db = db.Where("(SELECT COUNT(*) FROM fight_history_details WHERE fight_history_details.fight_history_id = fight_histories.id) = ?", req.Participants)
+	}
+
+	detail := fightHistoryDetailQuery(db)
+	hasDetailFilter := false
+	if req.Pokemon != "" {
+		detail = detail.Where("fight_history_details.pokemon = ?", req.Pokemon)
+		hasDetailFilter = true
+		if req.Cancelled != nil {
+			detail = detail.Where("fight_history_details.cancelled = ?", *req.Cancelled)
+		}
+	}
+	if req.MinScore != nil {
+		detail = detail.Where("fight_history_details.score >= ?", *req.MinScore)
+		hasDetailFilter = true
+	}
+	if hasDetailFilter {
+		db = db.Where("EXISTS (?)", detail)
+	}
+
+	if req.Pokemon == "" && req.Cancelled != nil {
+		cancelled := fightHistoryDetailQuery(db).Where("fight_history_details.cancelled = ?", true)
+		if *req.Cancelled {
+			db = db.Where("EXISTS (?)", cancelled)
+		} else {
+			db = db.Where("NOT EXISTS (?)", cancelled)
+		}
+	}
+
+	return db
+}
 
-	return fightHistoryDetail, nil
+func fightHistoryDetailQuery(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Table("fight_history_details").
+		Select("1").
+		Where("fight_history_details.fight_history_id = fight_histories.id")
 }
@@ -0,0 +1,236 @@
+package repository_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"path/filepath"
+	"pokeapi/entity"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"pokeapi/repository"
+	"testing"
+	"time"
+)
+
+// openTestDB opens a fresh sqlite database with the schema config.Connect
+// migrates. Row locks are no-ops in sqlite, but transactions and unique
+// indexes behave as in MySQL.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		SkipDefaultTransaction: true,
+		TranslateError:         true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(
+		&entity.Trainer{},
+		&entity.Season{},
+		&entity.SeasonStanding{},
+		&entity.FightHistory{},
+		&entity.FightHistoryDetail{},
+		&entity.BattleTurn{},
+		&entity.FightTeam{},
+		&entity.FightTeamMember{},
+		&entity.CancellationAudit{},
+		&entity.CancellationAuditChange{},
+		&entity.PokemonRating{},
+		&entity.RatingHistory{},
+	)
+	require.NoError(t, err)
+	return db
+}
+
+// insertTestFight records a fight ranking names in order with linear scoring.
+func insertTestFight(t *testing.T, r repository.PokeRepository, names ...string) entity.FightHistory {
+	t.Helper()
+	fightHistory := entity.FightHistory{Mode: "cp", ScoringRule: pokemon.ScoringLinear}
+	for i, name := range names {
+		fightHistory.FightHistoryDetail = append(fightHistory.FightHistoryDetail, entity.FightHistoryDetail{
+			Pokemon: name,
+			Slot:    i,
+			Rank:    i + 1,
+			Score:   float64(5 - i),
+		})
+	}
+
+	fightHistory, err := r.InsertFight(fightHistory)
+	require.NoError(t, err)
+	return fightHistory
+}
+
+func countRows(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	t.Helper()
+	var count int64
+	require.NoError(t, db.Model(model).Count(&count).Error)
+	return count
+}
+
+// fightRanks returns the rank, score and cancelled flag stored for every
+// participant of a fight.
+func fightRanks(t *testing.T, r repository.PokeRepository, id uint) map[string][3]interface{} {
+	t.Helper()
+	fightHistory, err := r.GetFightHistoryByID(id)
+	require.NoError(t, err)
+
+	ranks := make(map[string][3]interface{})
+	for _, d := range fightHistory.FightHistoryDetail {
+		ranks[d.Pokemon] = [3]interface{}{d.Rank, d.Score, d.Cancelled}
+	}
+	return ranks
+}
+
+func TestInsertFight(t *testing.T) {
+	db := openTestDB(t)
+	r := repository.NewPokeRepository(db)
+
+	fightHistory := insertTestFight(t, r, "pikachu", "eevee")
+	assert.NotZero(t, fightHistory.ID)
+	assert.EqualValues(t, 2, countRows(t, db, &entity.FightHistoryDetail{}))
+	assert.EqualValues(t, 2, countRows(t, db, &entity.RatingHistory{}))
+}
+
+func TestInsertFightRollsBack(t *testing.T) {
+	db := openTestDB(t)
+	r := repository.NewPokeRepository(db)
+
+	now := time.Now()
+	closed := entity.Season{Name: "closed", StartsAt: now.Add(-time.Hour), ClosedAt: &now}
+	require.NoError(t, db.Create(&closed).Error)
+	unknownTrainer := uint(42)
+
+	testTable := []struct {
+		name         string
+		fightHistory entity.FightHistory
+		expectedErr  error
+	}{
+		{
+			name:         "unknown trainer",
+			fightHistory: entity.FightHistory{TrainerID: &unknownTrainer},
+			expectedErr:  repository.ErrNotFound,
+		},
+		{
+			name:         "closed season",
+			fightHistory: entity.FightHistory{SeasonID: &closed.ID},
+			expectedErr:  repository.ErrConflict,
+		},
+		{
+			name: "unknown team trainer",
+			fightHistory: entity.FightHistory{
+				Mode:  pokemon.FightModeTeam,
+				Teams: []entity.FightTeam{{TrainerID: &unknownTrainer}},
+			},
+			expectedErr: repository.ErrNotFound,
+		},
+	}
+	for _, test := range testTable {
+		test.fightHistory.FightHistoryDetail = []entity.FightHistoryDetail{
+			{Pokemon: "pikachu", Rank: 1, Score: 5},
+			{Pokemon: "eevee", Rank: 2, Score: 4},
+		}
+		_, err := r.InsertFight(test.fightHistory)
+		assert.ErrorIs(t, err, test.expectedErr, test.name)
+	}
+
+	assert.Zero(t, countRows(t, db, &entity.FightHistory{}))
+	assert.Zero(t, countRows(t, db, &entity.FightHistoryDetail{}))
+	assert.Zero(t, countRows(t, db, &entity.RatingHistory{}))
+}
+
+func TestCancelScorePokemon(t *testing.T) {
+	db := openTestDB(t)
+	r := repository.NewPokeRepository(db)
+	fightHistory := insertTestFight(t, r, "pikachu", "eevee", "onix")
+
+	detail, err := r.CancelScorePokemon(model.PokemonCancelReqBody{
+		FightHistoryID: int(fightHistory.ID),
+		Pokemon:        "pikachu",
+		Actor:          "admin",
+		Reason:         "disqualified",
+	})
+	assert.NoError(t, err)
+	assert.True(t, detail.Cancelled)
+	assert.Equal(t, map[string][3]interface{}{
+		"pikachu": {0, 0.0, true},
+		"eevee":   {1, 5.0, false},
+		"onix":    {2, 4.0, false},
+	}, fightRanks(t, r, fightHistory.ID))
+
+	audits, err := r.GetCancellationAudits(fightHistory.ID)
+	assert.NoError(t, err)
+	assert.Len(t, audits, 1)
+	assert.Equal(t, repository.AuditActionCancel, audits[0].Action)
+	assert.Equal(t, "pikachu", audits[0].Pokemon)
+	assert.Equal(t, "admin", audits[0].Actor)
+	assert.Len(t, audits[0].Changes, 3)
+
+	// pikachu is cancelled already and snorlax never fought
+	for _, name := range []string{"pikachu", "snorlax"} {
+		_, err = r.CancelScorePokemon(model.PokemonCancelReqBody{FightHistoryID: int(fightHistory.ID), Pokemon: name})
+		assert.ErrorIs(t, err, repository.ErrNotFound, name)
+	}
+	_, err = r.CancelScorePokemon(model.PokemonCancelReqBody{FightHistoryID: int(fightHistory.ID) + 1, Pokemon: "eevee"})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.EqualValues(t, 1, countRows(t, db, &entity.CancellationAudit{}))
+}
+
+func TestCancelScorePokemonTeamFight(t *testing.T) {
+	db := openTestDB(t)
+	r := repository.NewPokeRepository(db)
+	team := 0
+	fightHistory, err := r.InsertFight(entity.FightHistory{
+		Mode: pokemon.FightModeTeam,
+		FightHistoryDetail: []entity.FightHistoryDetail{
+			{Pokemon: "pikachu", Team: &team, Rank: 1, Score: 5},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = r.CancelScorePokemon(model.PokemonCancelReqBody{FightHistoryID: int(fightHistory.ID), Pokemon: "pikachu"})
+	assert.ErrorIs(t, err, repository.ErrConflict)
+	assert.Zero(t, countRows(t, db, &entity.CancellationAudit{}))
+}
+
+func TestRevertCancellation(t *testing.T) {
+	db := openTestDB(t)
+	r := repository.NewPokeRepository(db)
+	fightHistory := insertTestFight(t, r, "pikachu", "eevee", "onix")
+	original := fightRanks(t, r, fightHistory.ID)
+
+	cancel := func(name string) uint {
+		_, err := r.CancelScorePokemon(model.PokemonCancelReqBody{FightHistoryID: int(fightHistory.ID), Pokemon: name})
+		require.NoError(t, err)
+		audits, err := r.GetCancellationAudits(fightHistory.ID)
+		require.NoError(t, err)
+		return audits[len(audits)-1].ID
+	}
+	first := cancel("pikachu")
+	afterFirst := fightRanks(t, r, fightHistory.ID)
+	second := cancel("eevee")
+
+	// the fight changed after the first cancellation
+	_, err := r.RevertCancellation(first, model.CancelRevertReqBody{Actor: "admin"})
+	assert.ErrorIs(t, err, repository.ErrConflict)
+
+	revert, err := r.RevertCancellation(second, model.CancelRevertReqBody{Actor: "admin", Reason: "mistake"})
+	assert.NoError(t, err)
+	assert.Equal(t, repository.AuditActionRevert, revert.Action)
+	assert.Equal(t, &second, revert.RevertsID)
+	assert.Equal(t, afterFirst, fightRanks(t, r, fightHistory.ID))
+
+	_, err = r.RevertCancellation(second, model.CancelRevertReqBody{Actor: "admin"})
+	assert.ErrorIs(t, err, repository.ErrConflict)
+
+	_, err = r.RevertCancellation(first, model.CancelRevertReqBody{Actor: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, original, fightRanks(t, r, fightHistory.ID))
+
+	// only cancellations can be reverted
+	_, err = r.RevertCancellation(revert.ID, model.CancelRevertReqBody{Actor: "admin"})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.EqualValues(t, 4, countRows(t, db, &entity.CancellationAudit{}))
+}
@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pokeapi/entity"
+	"pokeapi/pokemon"
+	"sort"
+)
+
+type RatingRepository struct {
+	DB *gorm.DB
+}
+
+func NewRatingRepository(mysql *gorm.DB) RatingRepository {
+	return RatingRepository{
+		DB: mysql,
+	}
+}
+
+func (r RatingRepository) GetRatings(system string, offset int, limit int) ([]entity.PokemonRating, int64, error) {
+	var total int64
+	err := r.DB.Model(&entity.PokemonRating{}).Count(&total).Error
+	if err != nil {
+		return []entity.PokemonRating{}, 0, err
+	}
+
+	order := "elo DESC"
+	if system == pokemon.RatingSystemGlicko2 {
+		order = "glicko2_rating DESC"
+	}
+	db := r.DB.Order(order).Order("pokemon").Offset(offset)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	var ratings []entity.PokemonRating
+	err = db.Find(&ratings).Error
+	if err != nil {
+		return []entity.PokemonRating{}, 0, err
+	}
+	return ratings, total, nil
+}
+
+func (r RatingRepository) GetRatingHistory(name string) ([]entity.RatingHistory, error) {
+	var ratingHistories []entity.RatingHistory
+	err := r.DB.Where("pokemon = ?", name).Order("fight_history_id").Find(&ratingHistories).Error
+	if err != nil {
+		return []entity.RatingHistory{}, err
+	}
+	return ratingHistories, nil
+}
+
+func (r RatingRepository) GetRatingsByPokemon(names []string) (map[string]entity.PokemonRating, error) {
+	var stored []entity.PokemonRating
+	err := r.DB.Where("pokemon IN ?", names).Find(&stored).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[string]entity.PokemonRating, len(stored))
+	for _, rating := range stored {
+		ratings[rating.Pokemon] = rating
+	}
+	return ratings, nil
+}
+
+func (r RatingRepository) RecomputeRatings() (int, error) {
+	var rated int
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rated, err = recomputeRatings(tx)
+		return err
+	})
+	return rated, err
+}
+
+func newPokemonRating(name string) entity.PokemonRating {
+	glicko2 := pokemon.NewGlicko2Rating()
+	return entity.PokemonRating{
+		Pokemon:           name,
+		Elo:               pokemon.DefaultEloRating,
+		Glicko2Rating:     glicko2.Rating,
+		Glicko2Deviation:  glicko2.Deviation,
+		Glicko2Volatility: glicko2.Volatility,
+	}
+}
+
+// rateFight updates the ratings of the participants of one fight that were
+// not cancelled and returns their rating history rows.
+func rateFight(ratings map[string]*entity.PokemonRating, fightHistoryID uint, details []entity.FightHistoryDetail) []entity.RatingHistory {
+	var active []entity.FightHistoryDetail
+	for _, d := range details {
+		if !d.Cancelled {
+			active = append(active, d)
+		}
+	}
+	if len(active) < 2 {
+		return nil
+	}
+
+	elo := make([]float64, len(active))
+	glicko2 := make([]pokemon.Glicko2Rating, len(active))
+	ranks := make([]int, len(active))
+	for i, d := range active {
+		rating := ratings[d.Pokemon]
+		elo[i] = rating.Elo
+		glicko2[i] = pokemon.Glicko2Rating{
+			Rating:     rating.Glicko2Rating,
+			Deviation:  rating.Glicko2Deviation,
+			Volatility: rating.Glicko2Volatility,
+		}
+		ranks[i] = d.Rank
+	}
+	eloAfter := pokemon.EloUpdate(elo, ranks)
+	glicko2After := pokemon.Glicko2Update(glicko2, ranks)
+
+	ratingHistories := make([]entity.RatingHistory, 0, len(active))
+	for i, d := range active {
+		rating := ratings[d.Pokemon]
+		rating.Elo = eloAfter[i]
+		rating.Glicko2Rating = glicko2After[i].Rating
+		rating.Glicko2Deviation = glicko2After[i].Deviation
+		rating.Glicko2Volatility = glicko2After[i].Volatility
+		rating.Fights++
+
+		ratingHistories = append(ratingHistories, entity.RatingHistory{
+			FightHistoryID:      fightHistoryID,
+			Pokemon:             d.Pokemon,
+			Rank:                d.Rank,
+			EloBefore:           elo[i],
+			EloAfter:            eloAfter[i],
+			Glicko2RatingBefore: glicko2[i].Rating,
+			Glicko2RatingAfter:  glicko2After[i].Rating,
+			Glicko2Deviation:    glicko2After[i].Deviation,
+			Glicko2Volatility:   glicko2After[i].Volatility,
+		})
+	}
+	return ratingHistories
+}
+
+// applyFightRatings rates a newly recorded fight on top of the stored
+// ratings. The participants' rating rows are locked so concurrent fights and
+// recomputes are applied one after the other.
+func applyFightRatings(tx *gorm.DB, fightHistoryID uint, details []entity.FightHistoryDetail) error {
+	var names []string
+	for _, d := range details {
+		if !d.Cancelled {
+			names = append(names, d.Pokemon)
+		}
+	}
+	if len(names) < 2 {
+		return nil
+	}
+	sort.Strings(names)
+
+	defaults := make([]entity.PokemonRating, 0, len(names))
+	for _, name := range names {
+		defaults = append(defaults, newPokemonRating(name))
+	}
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaults).Error
+	if err != nil {
+		return err
+	}
+
+	var stored []entity.PokemonRating
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("pokemon IN ?", names).
+		Order("pokemon").
+		Find(&stored).Error
+	if err != nil {
+		return err
+	}
+
+	ratings := make(map[string]*entity.PokemonRating, len(stored))
+	for i := range stored {
+		ratings[stored[i].Pokemon] = &stored[i]
+	}
+	ratingHistories := rateFight(ratings, fightHistoryID, details)
+
+	err = tx.Save(&stored).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(&ratingHistories).Error
+}
+
+// recomputeRatings replays every fight in order from default ratings, e.g.
+// after a cancellation changed the placements of an old fight. Locking every
+// rating row first makes fights recorded meanwhile wait and then apply on top
+// of the recomputed ratings.
+func recomputeRatings(tx *gorm.DB) (int, error) {
+	var stored []entity.PokemonRating
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&stored).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var details []entity.FightHistoryDetail
+	err = tx.Where("fight_history_id NOT IN (?)", teamFights(tx)).Order("fight_history_id, id").Find(&details).Error
+	if err != nil {
+		return 0, err
+	}
+
+	ratings := make(map[string]*entity.PokemonRating)
+	var ratingHistories []entity.RatingHistory
+	rated := 0
+	for start := 0; start < len(details); {
+		end := start
+		for end < len(details) && details[end].FightHistoryID == details[start].FightHistoryID {
+			end++
+		}
+		fight := rankFightHistoryDetails(details[start:end])
+		for _, d := range fight {
+			if _, ok := ratings[d.Pokemon]; !ok && !d.Cancelled {
+				rating := newPokemonRating(d.Pokemon)
+				ratings[d.Pokemon] = &rating
+			}
+		}
+		if rows := rateFight(ratings, details[start].FightHistoryID, fight); len(rows) > 0 {
+			ratingHistories = append(ratingHistories, rows...)
+			rated++
+		}
+		start = end
+	}
+
+	err = tx.Where("1 = 1").Delete(&entity.RatingHistory{}).Error
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Where("1 = 1").Delete(&entity.PokemonRating{}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	recomputed := make([]entity.PokemonRating, 0, len(ratings))
+	for _, rating := range ratings {
+		recomputed = append(recomputed, *rating)
+	}
+	if len(recomputed) > 0 {
+		err = tx.CreateInBatches(&recomputed, 100).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(ratingHistories) > 0 {
+		err = tx.CreateInBatches(&ratingHistories, 100).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return rated, nil
+}
+
+// teamFights selects the ids of the team fights, which are not rated.
+func teamFights(tx *gorm.DB) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Model(&entity.FightHistory{}).
+		Select("id").
+		Where("mode = ?", pokemon.FightModeTeam)
+}
+
+// rerateFrom replays the fights from fightHistoryID onward, e.g. after a
+// cancellation changed its placements. Every Pokémon in those fights goes
+// back to its rating after its last earlier fight, taken from its rating
+// history, and the later rating history is rated again. Rating history
+// recorded before volatilities were stored cannot be restored from, in which
+// case all fights are replayed.
+func rerateFrom(tx *gorm.DB, fightHistoryID uint) error {
+	var details []entity.FightHistoryDetail
+	err := tx.Where("fight_history_id >= ? AND fight_history_id NOT IN (?)", fightHistoryID, teamFights(tx)).Order("fight_history_id, id").Find(&details).Error
+	if err != nil {
+		return err
+	}
+	var rerated []string
+	err = tx.Model(&entity.RatingHistory{}).
+		Where("fight_history_id >= ?", fightHistoryID).
+		Distinct().
+		Pluck("pokemon", &rerated).Error
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range rerated {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for _, d := range details {
+		if !seen[d.Pokemon] {
+			seen[d.Pokemon] = true
+			names = append(names, d.Pokemon)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	var stored []entity.PokemonRating
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("pokemon IN ?", names).
+		Order("pokemon").
+		Find(&stored).Error
+	if err != nil {
+		return err
+	}
+
+	latest := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&entity.RatingHistory{}).
+		Select("pokemon, MAX(fight_history_id) as fight_history_id, COUNT(*) as fights").
+		Where("pokemon IN ? AND fight_history_id < ?", names, fightHistoryID).
+		Group("pokemon")
+	var previous []struct {
+		entity.RatingHistory `gorm:"embedded"`
+		Fights               int
+	}
+	err = tx.Table("rating_histories").
+		Select("rating_histories.*, latest.fights").
+		Joins("JOIN (?) as latest ON latest.pokemon = rating_histories.pokemon AND latest.fight_history_id = rating_histories.fight_history_id", latest).
+		Scan(&previous).Error
+	if err != nil {
+		return err
+	}
+
+	ratings := make(map[string]*entity.PokemonRating, len(names))
+	for _, name := range names {
+		rating := newPokemonRating(name)
+		ratings[name] = &rating
+	}
+	for _, p := range previous {
+		if p.Glicko2Volatility == 0 {
+			_, err = recomputeRatings(tx)
+			return err
+		}
+		rating := ratings[p.Pokemon]
+		rating.Elo = p.EloAfter
+		rating.Glicko2Rating = p.Glicko2RatingAfter
+		rating.Glicko2Deviation = p.Glicko2Deviation
+		rating.Glicko2Volatility = p.Glicko2Volatility
+		rating.Fights = p.Fights
+	}
+
+	var ratingHistories []entity.RatingHistory
+	for start := 0; start < len(details); {
+		end := start
+		for end < len(details) && details[end].FightHistoryID == details[start].FightHistoryID {
+			end++
+		}
+		fight := rankFightHistoryDetails(details[start:end])
+		ratingHistories = append(ratingHistories, rateFight(ratings, details[start].FightHistoryID, fight)...)
+		start = end
+	}
+
+	err = tx.Where("fight_history_id >= ?", fightHistoryID).Delete(&entity.RatingHistory{}).Error
+	if err != nil {
+		return err
+	}
+
+	// a Pokémon without a rating is only given one once it is rated
+	rated := make(map[string]bool, len(stored))
+	for _, rating := range stored {
+		rated[rating.Pokemon] = true
+	}
+	var rerate []entity.PokemonRating
+	for _, name := range names {
+		if rated[name] || ratings[name].Fights > 0 {
+			rerate = append(rerate, *ratings[name])
+		}
+	}
+	if len(rerate) > 0 {
+		err = tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&rerate, 100).Error
+		if err != nil {
+			return err
+		}
+	}
+	if len(ratingHistories) > 0 {
+		return tx.CreateInBatches(&ratingHistories, 100).Error
+	}
+	return nil
+}
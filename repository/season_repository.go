@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pokeapi/entity"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"time"
+)
+
+type SeasonRepository struct {
+	DB *gorm.DB
+}
+
+func NewSeasonRepository(mysql *gorm.DB) SeasonRepository {
+	return SeasonRepository{
+		DB: mysql,
+	}
+}
+
+// InsertSeason opens a season. Only one season may be open at a time, so the
+// previous one has to be closed, and its leaderboard frozen, first. A previous
+// season that has reached its end date is closed here.
+func (r SeasonRepository) InsertSeason(season entity.Season, now time.Time) (entity.Season, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var open []entity.Season
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("closed_at IS NULL").
+			Find(&open).Error
+		if err != nil {
+			return err
+		}
+		for i := range open {
+			if open[i].EndsAt == nil || open[i].EndsAt.After(now) {
+				return fmt.Errorf("season %d is still open: %w", open[i].ID, ErrConflict)
+			}
+			err = closeSeason(tx, &open[i], now)
+			if err != nil {
+				return err
+			}
+		}
+
+		res := tx.Omit(clause.Associations).Create(&season)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("failed insert season data")
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.Season{}, err
+	}
+
+	return season, nil
+}
+
+// CloseSeason freezes the season leaderboard into SeasonStanding rows. Later
+// cancellations or rescoring no longer change a closed season.
+func (r SeasonRepository) CloseSeason(id uint, now time.Time) (entity.Season, error) {
+	var season entity.Season
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&season, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("season %d: %w", id, ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		if season.ClosedAt != nil {
+			return fmt.Errorf("season %d already closed: %w", id, ErrConflict)
+		}
+
+		return closeSeason(tx, &season, now)
+	})
+	if err != nil {
+		return entity.Season{}, err
+	}
+
+	return season, nil
+}
+
+// closeSeason freezes the standings of an open season locked in tx.
+func closeSeason(tx *gorm.DB, season *entity.Season, now time.Time) error {
+	leaderboard, err := sumScore(tx, model.LeaderboardReqQuery{SeasonID: &season.ID})
+	if err != nil {
+		return err
+	}
+
+	var standings []entity.SeasonStanding
+	for _, l := range pokemon.RankLeaderboard(leaderboard) {
+		standings = append(standings, entity.SeasonStanding{
+			SeasonID:     season.ID,
+			Rank:         l.Rank,
+			Pokemon:      l.Pokemon,
+			TotalScore:   l.TotalScore,
+			Fights:       l.Fights,
+			Wins:         l.Wins,
+			AverageScore: l.AverageScore,
+			WinRate:      l.WinRate,
+		})
+	}
+	if len(standings) > 0 {
+		err = tx.CreateInBatches(&standings, 100).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	season.ClosedAt = &now
+	if season.EndsAt == nil || season.EndsAt.After(now) {
+		season.EndsAt = &now
+	}
+	return tx.Model(season).Select("ClosedAt", "EndsAt").Updates(season).Error
+}
+
+func (r SeasonRepository) GetSeasons() ([]entity.Season, error) {
+	var seasons []entity.Season
+	err := r.DB.Order("id DESC").Find(&seasons).Error
+	if err != nil {
+		return []entity.Season{}, err
+	}
+	return seasons, nil
+}
+
+func (r SeasonRepository) GetSeasonByID(id uint) (entity.Season, error) {
+	var season entity.Season
+	err := r.DB.First(&season, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Season{}, fmt.Errorf("season %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return entity.Season{}, err
+	}
+	return season, nil
+}
+
+func (r SeasonRepository) GetSeasonStandings(id uint) ([]model.Leaderboard, error) {
+	var standings []entity.SeasonStanding
+	err := r.DB.Where("season_id = ?", id).Order("`rank`, pokemon").Find(&standings).Error
+	if err != nil {
+		return []model.Leaderboard{}, err
+	}
+
+	leaderboard := make([]model.Leaderboard, 0, len(standings))
+	for _, s := range standings {
+		leaderboard = append(leaderboard, model.Leaderboard{
+			Rank:         s.Rank,
+			Pokemon:      s.Pokemon,
+			TotalScore:   s.TotalScore,
+			Fights:       s.Fights,
+			Wins:         s.Wins,
+			AverageScore: s.AverageScore,
+			WinRate:      s.WinRate,
+		})
+	}
+	return leaderboard, nil
+}
+
+func (r SeasonRepository) GetSeasonSumScore(id uint) ([]model.Leaderboard, error) {
+	return sumScore(r.DB, model.LeaderboardReqQuery{SeasonID: &id})
+}
@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pokeapi/entity"
+	"pokeapi/repository"
+	"testing"
+	"time"
+)
+
+func TestInsertSeasonConflict(t *testing.T) {
+	db := openTestDB(t)
+	r := repository.NewSeasonRepository(db)
+	now := time.Now()
+
+	_, err := r.InsertSeason(entity.Season{Name: "one", StartsAt: now}, now)
+	require.NoError(t, err)
+
+	_, err = r.InsertSeason(entity.Season{Name: "two", StartsAt: now}, now)
+	assert.ErrorIs(t, err, repository.ErrConflict)
+	assert.EqualValues(t, 1, countRows(t, db, &entity.Season{}))
+}
+
+func TestInsertSeasonClosesEndedSeason(t *testing.T) {
+	db := openTestDB(t)
+	r := repository.NewSeasonRepository(db)
+	fights := repository.NewPokeRepository(db)
+	now := time.Now()
+	endsAt := now.Add(time.Hour)
+
+	first, err := r.InsertSeason(entity.Season{Name: "one", StartsAt: now.Add(-time.Hour), EndsAt: &endsAt}, now)
+	require.NoError(t, err)
+	_, err = fights.InsertFight(entity.FightHistory{
+		SeasonID: &first.ID,
+		FightHistoryDetail: []entity.FightHistoryDetail{
+			{Pokemon: "pikachu", Rank: 1, Score: 5},
+			{Pokemon: "eevee", Rank: 2, Score: 4},
+		},
+	})
+	require.NoError(t, err)
+
+	// the first season is still running
+	_, err = r.InsertSeason(entity.Season{Name: "two", StartsAt: endsAt}, now)
+	assert.ErrorIs(t, err, repository.ErrConflict)
+
+	later := endsAt.Add(time.Minute)
+	second, err := r.InsertSeason(entity.Season{Name: "two", StartsAt: endsAt}, later)
+	assert.NoError(t, err)
+	assert.NotZero(t, second.ID)
+
+	first, err = r.GetSeasonByID(first.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, first.ClosedAt) {
+		assert.WithinDuration(t, later, *first.ClosedAt, time.Second)
+	}
+	assert.WithinDuration(t, endsAt, *first.EndsAt, time.Second)
+
+	standings, err := r.GetSeasonStandings(first.ID)
+	assert.NoError(t, err)
+	assert.Len(t, standings, 2)
+
+	// fights can no longer join the closed season
+	_, err = fights.InsertFight(entity.FightHistory{SeasonID: &first.ID})
+	assert.ErrorIs(t, err, repository.ErrConflict)
+}
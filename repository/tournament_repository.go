@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pokeapi/entity"
+)
+
+type TournamentRepository struct {
+	DB *gorm.DB
+}
+
+func NewTournamentRepository(mysql *gorm.DB) TournamentRepository {
+	return TournamentRepository{
+		DB: mysql,
+	}
+}
+
+func (r TournamentRepository) InsertTournament(tournament entity.Tournament) (entity.Tournament, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if tournament.TrainerID != nil {
+			var trainer entity.Trainer
+			err := tx.Select("id").First(&trainer, *tournament.TrainerID).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("trainer %d: %w", *tournament.TrainerID, ErrNotFound)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&tournament).Error
+	})
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+
+	return tournament, nil
+}
+
+func (r TournamentRepository) GetTournaments() ([]entity.Tournament, error) {
+	var tournaments []entity.Tournament
+	err := r.DB.Order("id DESC").Find(&tournaments).Error
+	if err != nil {
+		return []entity.Tournament{}, err
+	}
+	return tournaments, nil
+}
+
+func (r TournamentRepository) GetTournamentByID(id uint) (entity.Tournament, error) {
+	return getTournament(r.DB, id)
+}
+
+// UpdateTournament applies update to a tournament while holding its row lock,
+// so registrations and rounds are never drawn twice. Matches that come back
+// with a Fight get it recorded as a fight history in the same transaction.
+func (r TournamentRepository) UpdateTournament(id uint, update func(entity.Tournament) (entity.Tournament, error)) (entity.Tournament, error) {
+	var tournament entity.Tournament
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := getTournament(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+		if err != nil {
+			return err
+		}
+
+		tournament, err = update(locked)
+		if err != nil {
+			return err
+		}
+
+		for i, m := range tournament.Matches {
+			if m.Fight == nil || m.FightHistoryID != nil {
+				continue
+			}
+			fightHistory, err := insertFight(tx, *m.Fight)
+			if err != nil {
+				return err
+			}
+			tournament.Matches[i].FightHistoryID = &fightHistory.ID
+		}
+
+		for i := range tournament.Entrants {
+			tournament.Entrants[i].TournamentID = tournament.ID
+		}
+		if len(tournament.Entrants) > 0 {
+			err = tx.Save(&tournament.Entrants).Error
+			if err != nil {
+				return err
+			}
+		}
+		for i := range tournament.Matches {
+			tournament.Matches[i].TournamentID = tournament.ID
+		}
+		if len(tournament.Matches) > 0 {
+			err = tx.Save(&tournament.Matches).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit(clause.Associations).Save(&tournament).Error
+	})
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+
+	return tournament, nil
+}
+
+func getTournament(db *gorm.DB, id uint) (entity.Tournament, error) {
+	var tournament entity.Tournament
+	err := db.First(&tournament, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Tournament{}, fmt.Errorf("tournament %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+
+	err = db.Session(&gorm.Session{NewDB: true}).Where("tournament_id = ?", id).Order("seed, id").Find(&tournament.Entrants).Error
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+	err = db.Session(&gorm.Session{NewDB: true}).Where("tournament_id = ?", id).Order("round, position").Find(&tournament.Matches).Error
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+	return tournament, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"pokeapi/entity"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+)
+
+type TrainerRepository struct {
+	DB *gorm.DB
+}
+
+func NewTrainerRepository(mysql *gorm.DB) TrainerRepository {
+	return TrainerRepository{
+		DB: mysql,
+	}
+}
+
+// InsertTrainer relies on the unique index on trainers.name to reject a
+// duplicate name, so two concurrent registrations cannot both succeed.
+func (r TrainerRepository) InsertTrainer(trainer entity.Trainer) (entity.Trainer, error) {
+	err := r.DB.Create(&trainer).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return entity.Trainer{}, fmt.Errorf("trainer %q: %w", trainer.Name, ErrConflict)
+	}
+	if err != nil {
+		return entity.Trainer{}, err
+	}
+	return trainer, nil
+}
+
+func (r TrainerRepository) GetTrainerByID(id uint) (entity.Trainer, error) {
+	var trainer entity.Trainer
+	err := r.DB.First(&trainer, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Trainer{}, fmt.Errorf("trainer %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return entity.Trainer{}, err
+	}
+	return trainer, nil
+}
+
+func (r TrainerRepository) GetTrainerFights(id uint) ([]entity.FightHistory, error) {
+	var fightHistories []entity.FightHistory
+	teams := r.DB.Session(&gorm.Session{NewDB: true}).
+		Model(&entity.FightTeam{}).
+		Select("fight_history_id").
+		Where("trainer_id = ?", id)
+	err := r.DB.Preload("FightHistoryDetail").Preload("Teams.Members").
+		Where("trainer_id = ?", id).
+		Or("id IN (?)", teams).
+		Order("id DESC").
+		Find(&fightHistories).Error
+	if err != nil {
+		return []entity.FightHistory{}, err
+	}
+	return fightHistories, nil
+}
+
+// GetTrainerSumScore totals the scores of the participants each trainer
+// entered in fights and battles, plus the score of each team the trainer
+// fielded. Team members carry their team's score, so team fights are counted
+// once per team from fight_teams instead.
+func (r TrainerRepository) GetTrainerSumScore() ([]model.TrainerLeaderboard, error) {
+	var leaderboard []model.TrainerLeaderboard
+	details := r.DB.Session(&gorm.Session{NewDB: true}).
+		Table("fight_history_details").
+		Select("fight_history_details.trainer_id, fight_history_details.fight_history_id, fight_history_details.score").
+		Joins("JOIN fight_histories ON fight_histories.id = fight_history_details.fight_history_id").
+		Where("fight_histories.mode <> ?", pokemon.FightModeTeam)
+	teams := r.DB.Session(&gorm.Session{NewDB: true}).
+		Table("fight_teams").
+		Select("fight_teams.trainer_id, fight_teams.fight_history_id, fight_teams.score")
+	err := r.DB.Table("(? UNION ALL ?) as scores", details, teams).
+		Select("trainers.id as trainer_id, trainers.name as trainer, COUNT(DISTINCT scores.fight_history_id) as fights, SUM(scores.score) as total_score").
+		Joins("JOIN trainers ON trainers.id = scores.trainer_id").
+		Group("trainers.id, trainers.name").
+		Order("total_score DESC").
+		Scan(&leaderboard).Error
+	if err != nil {
+		return []model.TrainerLeaderboard{}, err
+	}
+
+	return leaderboard, nil
+}
+
+func (r TrainerRepository) GetTeamSumScore() ([]model.TeamLeaderboard, error) {
+	var leaderboard []model.TeamLeaderboard
+	err := r.DB.Table("fight_teams").
+		Select("trainers.id as trainer_id, trainers.name as trainer, COUNT(*) as fights, " +
+			"SUM(CASE WHEN fight_teams.`rank` = 1 THEN 1 ELSE 0 END) as wins, " +
+			"SUM(fight_teams.duel_wins) as duel_wins, SUM(fight_teams.score) as total_score").
+		Joins("JOIN trainers ON trainers.id = fight_teams.trainer_id").
+		Group("trainers.id, trainers.name").
+		Order("total_score DESC").
+		Order("wins DESC").
+		Scan(&leaderboard).Error
+	if err != nil {
+		return []model.TeamLeaderboard{}, err
+	}
+
+	return leaderboard, nil
+}
@@ -0,0 +1,27 @@
+package repository_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/entity"
+	"pokeapi/repository"
+	"testing"
+)
+
+func TestInsertTrainer(t *testing.T) {
+	db := openTestDB(t)
+	r := repository.NewTrainerRepository(db)
+
+	trainer, err := r.InsertTrainer(entity.Trainer{Name: "ash"})
+	assert.NoError(t, err)
+	assert.NotZero(t, trainer.ID)
+
+	_, err = r.InsertTrainer(entity.Trainer{Name: "ash"})
+	assert.ErrorIs(t, err, repository.ErrConflict)
+	assert.EqualValues(t, 1, countRows(t, db, &entity.Trainer{}))
+
+	// other database errors are not conflicts
+	db.Exec("DROP TABLE trainers")
+	_, err = r.InsertTrainer(entity.Trainer{Name: "misty"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, repository.ErrConflict)
+}
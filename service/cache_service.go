@@ -0,0 +1,28 @@
+package service
+
+import (
+	"pokeapi/model"
+	"pokeapi/repository"
+)
+
+type CacheService struct {
+	Cache *repository.CachedPokeDataSource
+}
+
+func NewCacheService(cache *repository.CachedPokeDataSource) CacheService {
+	return CacheService{
+		Cache: cache,
+	}
+}
+
+func (s CacheService) Stats() model.CacheStats {
+	return s.Cache.Stats()
+}
+
+func (s CacheService) PurgeEntry(key string) (bool, error) {
+	return s.Cache.Delete(key)
+}
+
+func (s CacheService) PurgeAll() error {
+	return s.Cache.Purge()
+}
@@ -1,28 +1,43 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 	"pokeapi/entity"
+	"pokeapi/helper"
 	"pokeapi/model"
 	"pokeapi/pokemon"
 	"pokeapi/repository"
+	"sort"
 	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidCPFormula   = errors.New("invalid cp formula")
+	ErrInvalidScoringRule = errors.New("invalid scoring rule")
+	ErrInvalidFightMode   = errors.New("invalid fight mode")
 )
 
 type PokeService struct {
 	Pokemon        pokemon.Pokemon
 	PokeRepository repository.PokeRepository
+	PokeDataSource repository.PokeDataSource
+	FightConfig    model.FightConfig
 }
 
-func NewPokeService(pokeRepository *repository.PokeRepository) PokeService {
+func NewPokeService(pokeRepository *repository.PokeRepository, pokeDataSource repository.PokeDataSource, fightConfig model.FightConfig) PokeService {
 	return PokeService{
 		PokeRepository: *pokeRepository,
+		PokeDataSource: pokeDataSource,
+		FightConfig:    fightConfig,
 	}
 }
 
 func (s PokeService) GetListPokemon(page int) ([]string, model.PokeDataSourceRes, error) {
 	offset := (page - 1) * 10
-	pokeApiRes, err := s.PokeRepository.GetAllPokemon(offset)
+	pokeApiRes, err := s.PokeDataSource.GetAllPokemon(offset)
 	if err != nil {
 		return nil, model.PokeDataSourceRes{}, err
 	}
@@ -30,89 +45,1033 @@ func (s PokeService) GetListPokemon(page int) ([]string, model.PokeDataSourceRes
 	return pokeRes, pokeApiRes, nil
 }
 
-func (s PokeService) GetPokemonData(name string) (model.Pokemon, error) {
-	pokeApiDetailRes, err := s.PokeRepository.GetOnePokemon(name)
+// GetPokemonData fetches a Pokémon. Its move set is only needed to battle,
+// so it is fetched only withMoves.
+func (s PokeService) GetPokemonData(name string, withMoves bool) (model.Pokemon, error) {
+	name, ok := helper.NormalizePokemonName(name)
+	if !ok {
+		return model.Pokemon{}, fmt.Errorf("%q: %w", name, repository.ErrNotFound)
+	}
+
+	pokeApiDetailRes, err := s.PokeDataSource.GetOnePokemon(name)
 	if err != nil {
 		return model.Pokemon{}, err
 	}
 	pokeDetailRes := s.Pokemon.PokemonDetailDataSourceToPokemon(pokeApiDetailRes)
+	if !withMoves {
+		return pokeDetailRes, nil
+	}
+	pokeDetailRes.Moves, err = s.getMoveSet(s.Pokemon.LevelUpMoves(pokeApiDetailRes, pokemon.BattleLevel))
+	if err != nil {
+		return model.Pokemon{}, err
+	}
 	return pokeDetailRes, nil
 }
 
-func (s PokeService) FightPokemon(pokemon []string) ([]model.Pokemon, error) {
-	var wg sync.WaitGroup
-	var mutex sync.Mutex
-	var err error
-	var listPoke []model.Pokemon
+// GetPokemonDetail returns a Pokémon with its combat power from the named
+// formula, or the configured one, applied to its base stats.
+func (s PokeService) GetPokemonDetail(name string, cpFormula string) (model.Pokemon, error) {
+	formula, err := s.cpFormula(cpFormula)
+	if err != nil {
+		return model.Pokemon{}, err
+	}
 
-	for _, p := range pokemon {
+	pokeDetailRes, err := s.GetPokemonData(name, true)
+	if err != nil {
+		return model.Pokemon{}, err
+	}
+	pokeDetailRes.CombatPower = formula.CombatPower(pokeDetailRes)
+	return pokeDetailRes, nil
+}
+
+func (s PokeService) GetSpecies(name string) (model.Species, error) {
+	name, ok := helper.NormalizePokemonName(name)
+	if !ok {
+		return model.Species{}, fmt.Errorf("%q: %w", name, repository.ErrNotFound)
+	}
+
+	speciesRes, err := s.PokeDataSource.GetSpecies(name)
+	if err != nil {
+		return model.Species{}, err
+	}
+	return s.Pokemon.SpeciesDataSourceToSpecies(speciesRes), nil
+}
+
+// GetEvolutions returns the evolution chain of a Pokémon's species. Every
+// species in it is represented by its default variety, whose combat power
+// comes from the named formula, or the configured one, applied to its base
+// stats.
+func (s PokeService) GetEvolutions(name string, cpFormula string) (model.EvolutionChain, error) {
+	formula, err := s.cpFormula(cpFormula)
+	if err != nil {
+		return model.EvolutionChain{}, err
+	}
+
+	name, ok := helper.NormalizePokemonName(name)
+	if !ok {
+		return model.EvolutionChain{}, fmt.Errorf("%q: %w", name, repository.ErrNotFound)
+	}
+	pokeApiDetailRes, err := s.PokeDataSource.GetOnePokemon(name)
+	if err != nil {
+		return model.EvolutionChain{}, err
+	}
+	species, err := s.GetSpecies(pokeApiDetailRes.Species.Name)
+	if err != nil {
+		return model.EvolutionChain{}, err
+	}
+	if species.EvolutionChainID == 0 {
+		return model.EvolutionChain{}, fmt.Errorf("species %s has no evolution chain: %w", species.Name, repository.ErrNotFound)
+	}
+	chainRes, err := s.PokeDataSource.GetEvolutionChain(species.EvolutionChainID)
+	if err != nil {
+		return model.EvolutionChain{}, err
+	}
+
+	evolutions := s.Pokemon.EvolutionChainDataSourceToEvolutions(chainRes)
+	var wg sync.WaitGroup
+	errs := make([]error, len(evolutions))
+	for i := range evolutions {
 		wg.Add(1)
-		go func(name string) {
+		go func(e *model.Evolution, err *error) {
 			defer wg.Done()
-			pokeData, err := s.GetPokemonData(name)
-			if err != nil {
-				return
+			*err = s.evolutionCombatPower(e, formula)
+		}(&evolutions[i], &errs[i])
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return model.EvolutionChain{}, err
+	}
+	pokemon.EvolutionCPDeltas(evolutions)
+
+	return model.EvolutionChain{
+		ID:         chainRes.ID,
+		Pokemon:    pokeApiDetailRes.Name,
+		Species:    species.Name,
+		CPFormula:  formula.Name(),
+		Evolutions: evolutions,
+	}, nil
+}
+
+func (s PokeService) evolutionCombatPower(evolution *model.Evolution, formula pokemon.CPFormula) error {
+	species, err := s.GetSpecies(evolution.Species)
+	if err != nil {
+		return err
+	}
+	evolution.Pokemon = species.Name
+	if len(species.Varieties) > 0 {
+		evolution.Pokemon = species.Varieties[0]
+	}
+
+	pokeApiDetailRes, err := s.PokeDataSource.GetOnePokemon(evolution.Pokemon)
+	if err != nil {
+		return err
+	}
+	evolution.CombatPower = formula.CombatPower(s.Pokemon.PokemonDetailDataSourceToPokemon(pokeApiDetailRes))
+	return nil
+}
+
+// moveFetchBatch is how many moves getMoveSet fetches at once.
+const moveFetchBatch = 4
+
+// getMoveSet keeps the first pokemon.MoveSetSize moves, in the given order,
+// that deal damage. Moves are fetched concurrently in small batches, and no
+// more batches are fetched once the move set is complete. Moves missing from
+// the data source are skipped, and a failed fetch only matters when the move
+// set is not complete before it.
+func (s PokeService) getMoveSet(names []string) ([]model.Move, error) {
+	var moves []model.Move
+	for start := 0; start < len(names) && len(moves) < pokemon.MoveSetSize; start += moveFetchBatch {
+		batch := names[start:]
+		if len(batch) > moveFetchBatch {
+			batch = batch[:moveFetchBatch]
+		}
+
+		var wg sync.WaitGroup
+		moveRes := make([]model.PokeMoveDataSourceRes, len(batch))
+		errs := make([]error, len(batch))
+		for i, n := range batch {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				moveRes[i], errs[i] = s.PokeDataSource.GetMove(name)
+			}(i, n)
+		}
+		wg.Wait()
+
+		for i := range batch {
+			if len(moves) == pokemon.MoveSetSize {
+				break
+			}
+			if errors.Is(errs[i], repository.ErrNotFound) {
+				continue
+			}
+			if errs[i] != nil {
+				return nil, errs[i]
 			}
-			mutex.Lock()
-			listPoke = append(listPoke, pokeData)
-			mutex.Unlock()
-		}(p)
+			if move := s.Pokemon.MoveDataSourceToMove(moveRes[i]); pokemon.IsDamagingMove(move) {
+				moves = append(moves, move)
+			}
+		}
 	}
+	return moves, nil
+}
 
-	wg.Wait()
+func (s PokeService) FightPokemon(req model.PokemonCreateReqBody) (model.FightResult, error) {
+	mode := req.Mode
+	if mode == "" {
+		mode = pokemon.FightModeCombatPower
+	}
+	if mode != pokemon.FightModeCombatPower && mode != pokemon.FightModeType {
+		return model.FightResult{}, fmt.Errorf("%w: %q", ErrInvalidFightMode, mode)
+	}
+	seed := newSeed(req.Seed)
+	seasonID, scoringRule, err := s.seasonScoringRule(req.Scoring)
+	if err != nil {
+		return model.FightResult{}, err
+	}
+	formula, err := s.cpFormula(req.CPFormula)
+	if err != nil {
+		return model.FightResult{}, err
+	}
+
+	listPoke, err := s.getParticipants(req.Pokemon, s.FightConfig.MinParticipants, s.FightConfig.MaxParticipants, false)
+	if err != nil {
+		return model.FightResult{}, err
+	}
+	listPoke, err = s.applyStatSpecs(req.Pokemon, listPoke, req.Specs, formula)
+	if err != nil {
+		return model.FightResult{}, err
+	}
+	owned, err := trainerPokemon(req.Pokemon, listPoke, req.TrainerID, req.TrainerPokemon)
+	if err != nil {
+		return model.FightResult{}, err
+	}
+
+	tieBreakers := s.tieBreakers()
+	result, err := s.Pokemon.Fight(mode, append([]model.Pokemon(nil), listPoke...), tieBreakers, seed)
+	if err != nil {
+		return model.FightResult{}, err
+	}
+
+	fightHistory, err := s.recordFight(entity.FightHistory{
+		Mode:          mode,
+		Seed:          seed,
+		EngineVersion: pokemon.EngineVersion,
+		CPFormula:     formula.Name(),
+		TieBreakers:   pokemon.FormatTieBreakers(tieBreakers),
+		TrainerID:     req.TrainerID,
+		SeasonID:      seasonID,
+	}, scoringRule, listPoke, result, owned)
+	if err != nil {
+		return model.FightResult{}, err
+	}
+
+	fightResult := model.FightResult{
+		FightHistoryID: fightHistory.ID,
+		TrainerID:      fightHistory.TrainerID,
+		Mode:           mode,
+		Seed:           seed,
+		EngineVersion:  pokemon.EngineVersion,
+		ScoringRule:    fightHistory.ScoringRule,
+		CPFormula:      fightHistory.CPFormula,
+		Pokemon:        result,
+	}
+	for _, d := range fightHistory.FightHistoryDetail {
+		fightResult.Standings = append(fightResult.Standings, model.Standing{
+			Rank:    d.Rank,
+			Pokemon: d.Pokemon,
+			Score:   d.Score,
+		})
+	}
+
+	return fightResult, nil
+}
+
+func (s PokeService) Battle(req model.BattleReqBody) (model.BattleResult, error) {
+	seed := newSeed(req.Seed)
+	seasonID, scoringRule, err := s.seasonScoringRule(req.Scoring)
+	if err != nil {
+		return model.BattleResult{}, err
+	}
+
+	formula, err := s.cpFormula("")
+	if err != nil {
+		return model.BattleResult{}, err
+	}
+
+	listPoke, err := s.getParticipants(req.Pokemon, 2, 2, true)
+	if err != nil {
+		return model.BattleResult{}, err
+	}
+	listPoke, err = s.applyStatSpecs(req.Pokemon, listPoke, req.Specs, formula)
+	if err != nil {
+		return model.BattleResult{}, err
+	}
+	owned, err := trainerPokemon(req.Pokemon, listPoke, req.TrainerID, req.TrainerPokemon)
+	if err != nil {
+		return model.BattleResult{}, err
+	}
+
+	result := s.Pokemon.Battle(listPoke[0], listPoke[1], seed)
+
+	ranked := listPoke
+	if result.Winner != listPoke[0].Name {
+		ranked = []model.Pokemon{listPoke[1], listPoke[0]}
+	}
+	fightHistory, err := s.recordFight(entity.FightHistory{
+		Mode:          pokemon.FightModeBattle,
+		Seed:          seed,
+		EngineVersion: pokemon.EngineVersion,
+		CPFormula:     formula.Name(),
+		TrainerID:     req.TrainerID,
+		SeasonID:      seasonID,
+		BattleTurns:   battleTurns(result),
+	}, scoringRule, listPoke, ranked, owned)
+	if err != nil {
+		return model.BattleResult{}, err
+	}
+
+	result.FightHistoryID = fightHistory.ID
+	result.CPFormula = fightHistory.CPFormula
+	return result, nil
+}
+
+// TeamFight validates and fetches the teams, settles the fight and records
+// it with one detail per member, all sharing their team's rank and score.
+func (s PokeService) TeamFight(req model.TeamFightReqBody) (model.TeamFightResult, error) {
+	resolution := req.Resolution
+	if resolution == "" {
+		resolution = pokemon.TeamResolutionSumCP
+	}
+	if !pokemon.IsValidTeamResolution(resolution) {
+		return model.TeamFightResult{}, &model.ParticipantError{
+			Message: fmt.Sprintf("unknown team resolution %q", resolution),
+		}
+	}
+	if len(req.Teams) < 2 || len(req.Teams) > s.FightConfig.MaxParticipants {
+		return model.TeamFightResult{}, &model.ParticipantError{
+			Message: fmt.Sprintf("a team fight needs between 2 and %d teams, got %d", s.FightConfig.MaxParticipants, len(req.Teams)),
+		}
+	}
+
+	size := len(req.Teams[0].Pokemon)
+	trainers := make(map[uint]bool)
+	var names []string
+	for _, team := range req.Teams {
+		if team.TrainerID == nil {
+			return model.TeamFightResult{}, &model.ParticipantError{
+				Message: "every team needs a trainer_id",
+			}
+		}
+		if trainers[*team.TrainerID] {
+			return model.TeamFightResult{}, &model.ParticipantError{
+				Message: fmt.Sprintf("trainer %d has more than one team", *team.TrainerID),
+			}
+		}
+		trainers[*team.TrainerID] = true
+
+		if len(team.Pokemon) < 1 || len(team.Pokemon) > pokemon.MaxTeamSize || len(team.Pokemon) != size {
+			return model.TeamFightResult{}, &model.ParticipantError{
+				Message: fmt.Sprintf("teams must have the same size, between 1 and %d Pokemon", pokemon.MaxTeamSize),
+			}
+		}
+		names = append(names, team.Pokemon...)
+	}
+
+	seed := newSeed(req.Seed)
+	seasonID, scoringRule, err := s.seasonScoringRule(req.Scoring)
+	if err != nil {
+		return model.TeamFightResult{}, err
+	}
+	formula, err := s.cpFormula(req.CPFormula)
+	if err != nil {
+		return model.TeamFightResult{}, err
+	}
 
+	listPoke, err := s.getTeamParticipants(names, size, resolution != pokemon.TeamResolutionSumCP)
+	if err != nil {
+		return model.TeamFightResult{}, err
+	}
+	listPoke, err = s.applyStatSpecs(names, listPoke, req.Specs, formula)
+	if err != nil {
+		return model.TeamFightResult{}, err
+	}
+
+	result, err := s.Pokemon.TeamFight(resolution, splitTeams(listPoke, size), req.BestOf, seed)
+	if err != nil {
+		return model.TeamFightResult{}, &model.ParticipantError{
+			Message: err.Error(),
+		}
+	}
+	for i := range result.Teams {
+		result.Teams[i].TrainerID = req.Teams[result.Teams[i].Team].TrainerID
+	}
+
+	fightHistory, err := s.PokeRepository.InsertFight(newTeamFightHistory(entity.FightHistory{
+		Mode:           pokemon.FightModeTeam,
+		Seed:           seed,
+		EngineVersion:  pokemon.EngineVersion,
+		CPFormula:      formula.Name(),
+		SeasonID:       seasonID,
+		TeamResolution: resolution,
+		BestOf:         result.BestOf,
+	}, scoringRule, listPoke, result))
+	if err != nil {
+		return model.TeamFightResult{}, err
+	}
+
+	result.FightHistoryID = fightHistory.ID
+	result.ScoringRule = fightHistory.ScoringRule
+	result.CPFormula = fightHistory.CPFormula
+	for i, team := range fightHistory.Teams {
+		result.Teams[i].Score = team.Score
+	}
+	return result, nil
+}
+
+func splitTeams(listPoke []model.Pokemon, size int) [][]model.Pokemon {
+	var teams [][]model.Pokemon
+	for start := 0; start < len(listPoke); start += size {
+		teams = append(teams, listPoke[start:start+size])
+	}
+	return teams
+}
+
+// newTeamFightHistory records the ranked teams and a detail for every member.
+// Detail slots number the members team after team in the order they were
+// submitted, as in listPoke, so a replay can rebuild the teams.
+func newTeamFightHistory(fightHistory entity.FightHistory, scoringRule pokemon.ScoringRule, listPoke []model.Pokemon, result model.TeamFightResult) entity.FightHistory {
+	fightHistory.ScoringRule = scoringRule.Name()
+	shared := make(map[int]int)
+	for _, t := range result.Teams {
+		shared[t.Rank]++
+	}
+
+	for _, t := range result.Teams {
+		team := t.Team
+		fightTeam := entity.FightTeam{
+			Team:      team,
+			TrainerID: t.TrainerID,
+			Rank:      t.Rank,
+			Score:     pokemon.SharedScore(scoringRule, t.Rank, shared[t.Rank], len(result.Teams)),
+			Power:     t.Power,
+			MatchWins: t.MatchWins,
+			DuelWins:  t.DuelWins,
+		}
+		for _, m := range t.Members {
+			slot := team*len(t.Members) + m.Slot
+			fightTeam.Members = append(fightTeam.Members, entity.FightTeamMember{
+				Pokemon:     m.Pokemon,
+				Slot:        m.Slot,
+				CombatPower: m.CombatPower,
+				Duels:       m.Duels,
+				Wins:        m.Wins,
+				Losses:      m.Losses,
+				DamageDealt: m.DamageDealt,
+			})
+			fightHistory.FightHistoryDetail = append(fightHistory.FightHistoryDetail, withStatSpec(entity.FightHistoryDetail{
+				TrainerID: t.TrainerID,
+				Team:      &team,
+				Pokemon:   m.Pokemon,
+				Slot:      slot,
+				Rank:      fightTeam.Rank,
+				Score:     fightTeam.Score,
+			}, listPoke[slot]))
+		}
+		fightHistory.Teams = append(fightHistory.Teams, fightTeam)
+	}
+
+	return fightHistory
+}
+
+func (s PokeService) ReplayFight(id uint) (model.ReplayResult, error) {
+	fightHistory, err := s.PokeRepository.GetFightHistoryByID(id)
+	if err != nil {
+		return model.ReplayResult{}, err
+	}
+
+	details := append([]entity.FightHistoryDetail(nil), fightHistory.FightHistoryDetail...)
+	replay := model.ReplayResult{
+		FightHistoryID:       fightHistory.ID,
+		Mode:                 fightHistory.Mode,
+		Seed:                 fightHistory.Seed,
+		EngineVersion:        fightHistory.EngineVersion,
+		CurrentEngineVersion: pokemon.EngineVersion,
+	}
+	sort.SliceStable(details, func(i, j int) bool {
+		return details[i].ID < details[j].ID
+	})
+	for _, d := range details {
+		replay.Stored = append(replay.Stored, d.Pokemon)
+	}
+
+	sort.SliceStable(details, func(i, j int) bool {
+		return details[i].Slot < details[j].Slot
+	})
+	var names []string
+	for _, d := range details {
+		names = append(names, d.Pokemon)
+	}
+
+	// battles and team duels use the move sets
+	withMoves := fightHistory.Mode == pokemon.FightModeBattle || (fightHistory.Mode == pokemon.FightModeTeam && fightHistory.TeamResolution != pokemon.TeamResolutionSumCP)
+	listPoke, err := s.getPokemonsData(names, withMoves)
+	if err != nil {
+		return model.ReplayResult{}, err
+	}
+	formula, err := pokemon.NewCPFormula(fightHistory.CPFormula, nil)
+	if err != nil {
+		return model.ReplayResult{}, err
+	}
+	for i, d := range details {
+		// participants fought without a spec store no level
+		if d.Level == 0 {
+			listPoke[i].CombatPower = formula.CombatPower(listPoke[i])
+			continue
+		}
+		spec := model.StatSpec{
+			Level:  d.Level,
+			Nature: d.Nature,
+			IVs:    statMap(d.IVs),
+			EVs:    statMap(d.EVs),
+		}
+		listPoke[i], err = s.Pokemon.ApplyStatSpec(listPoke[i], spec, formula)
+		if err != nil {
+			return model.ReplayResult{}, err
+		}
+	}
+
+	replay.Match = true
+	if fightHistory.Mode == pokemon.FightModeTeam {
+		if len(fightHistory.Teams) < 2 {
+			return model.ReplayResult{}, fmt.Errorf("team fight %d does not have teams", id)
+		}
+		result, err := s.Pokemon.TeamFight(fightHistory.TeamResolution, splitTeams(listPoke, len(listPoke)/len(fightHistory.Teams)), fightHistory.BestOf, fightHistory.Seed)
+		if err != nil {
+			return model.ReplayResult{}, err
+		}
+		for _, team := range result.Teams {
+			stored := fightHistory.Teams[team.Team]
+			replay.Match = replay.Match && len(stored.Members) == len(team.Members)
+			for i := 0; replay.Match && i < len(team.Members); i++ {
+				m := team.Members[i]
+				replay.Match = m.Wins == stored.Members[i].Wins && m.Losses == stored.Members[i].Losses && m.DamageDealt == stored.Members[i].DamageDealt
+			}
+			for _, m := range team.Members {
+				replay.Replayed = append(replay.Replayed, m.Pokemon)
+			}
+		}
+	} else if fightHistory.Mode == pokemon.FightModeBattle {
+		if len(listPoke) != 2 {
+			return model.ReplayResult{}, fmt.Errorf("battle %d does not have two participants", id)
+		}
+		result := s.Pokemon.Battle(listPoke[0], listPoke[1], fightHistory.Seed)
+		replay.Replayed = []string{result.Winner, result.Loser}
+		replay.Match = len(result.Log) == len(fightHistory.BattleTurns)
+		for i := 0; replay.Match && i < len(result.Log); i++ {
+			stored := fightHistory.BattleTurns[i]
+			replay.Match = result.Log[i].Attacker == stored.Attacker && result.Log[i].Move == stored.Move && result.Log[i].Damage == stored.Damage
+		}
+	} else {
+		tieBreakers, err := pokemon.ParseTieBreakers(fightHistory.TieBreakers)
+		if err != nil {
+			return model.ReplayResult{}, err
+		}
+		result, err := s.Pokemon.Fight(fightHistory.Mode, listPoke, tieBreakers, fightHistory.Seed)
+		if err != nil {
+			return model.ReplayResult{}, err
+		}
+		for _, r := range result {
+			replay.Replayed = append(replay.Replayed, r.Name)
+		}
+	}
+
+	replay.Match = replay.Match && len(replay.Stored) == len(replay.Replayed)
+	for i := 0; replay.Match && i < len(replay.Stored); i++ {
+		replay.Match = replay.Stored[i] == replay.Replayed[i]
+	}
+
+	return replay, nil
+}
+
+// getParticipants validates and fetches the Pokémon taking part in a fight.
+// Names are normalized before fetching and compared again afterwards, so
+// "25" and "pikachu" count as the same participant.
+func (s PokeService) getParticipants(names []string, min int, max int, withMoves bool) ([]model.Pokemon, error) {
+	if len(names) < min || len(names) > max {
+		return nil, &model.ParticipantError{
+			Message: fmt.Sprintf("a fight needs between %d and %d Pokemon, got %d", min, max, len(names)),
+		}
+	}
+
+	return s.getTeamParticipants(names, len(names), withMoves)
+}
+
+// getTeamParticipants validates and fetches names in consecutive teams of
+// size. Teams may field the same Pokémon, but a team fields each one once.
+func (s PokeService) getTeamParticipants(names []string, size int, withMoves bool) ([]model.Pokemon, error) {
+	participantErr := &model.ParticipantError{
+		Message: "invalid participants",
+	}
+	normalized := make([]string, len(names))
+	for i, n := range names {
+		name, ok := helper.NormalizePokemonName(n)
+		if !ok {
+			participantErr.Invalid = append(participantErr.Invalid, n)
+		}
+		normalized[i] = name
+	}
+	participantErr.Duplicated = teamDuplicates(normalized, size)
+	if len(participantErr.Invalid) > 0 || len(participantErr.Duplicated) > 0 {
+		return nil, participantErr
+	}
+
+	listPoke, err := s.getPokemonsData(normalized, withMoves)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(listPoke) != len(pokemon) {
-		return nil, fmt.Errorf("failed to fetch all Pokemon data")
+	var canonical []string
+	for _, p := range listPoke {
+		canonical = append(canonical, p.Name)
+	}
+	participantErr.Duplicated = teamDuplicates(canonical, size)
+	if len(participantErr.Duplicated) > 0 {
+		return nil, participantErr
+	}
+
+	return listPoke, nil
+}
+
+// teamDuplicates returns the names that appear more than once within one of
+// the consecutive teams of size.
+func teamDuplicates(names []string, size int) []string {
+	var duplicated []string
+	seen := make(map[string]bool)
+	for start := 0; start < len(names); start += size {
+		for _, d := range helper.DuplicateStrings(names[start : start+size]) {
+			if !seen[d] {
+				seen[d] = true
+				duplicated = append(duplicated, d)
+			}
+		}
+	}
+	return duplicated
+}
+
+// applyStatSpecs applies the requested specs to the participants fetched for
+// names and computes every participant's combat power with formula. A spec
+// names its Pokémon the same way a fight request does and applies to every
+// team fielding it; participants without one keep their base stats.
+func (s PokeService) applyStatSpecs(names []string, listPoke []model.Pokemon, specs []model.StatSpec, formula pokemon.CPFormula) ([]model.Pokemon, error) {
+	participants := make(map[string][]int, len(names))
+	for i, n := range names {
+		name, _ := helper.NormalizePokemonName(n)
+		participants[name] = append(participants[name], i)
+	}
+
+	participantErr := &model.ParticipantError{
+		Message: "specs must name participants of the fight, once each",
+	}
+	specified := make(map[int]model.StatSpec)
+	for _, spec := range specs {
+		name, _ := helper.NormalizePokemonName(spec.Pokemon)
+		indexes, ok := participants[name]
+		if !ok {
+			participantErr.Invalid = append(participantErr.Invalid, spec.Pokemon)
+			continue
+		}
+		if _, ok := specified[indexes[0]]; ok {
+			participantErr.Duplicated = append(participantErr.Duplicated, spec.Pokemon)
+			continue
+		}
+		for _, i := range indexes {
+			specified[i] = spec
+		}
+	}
+	if len(participantErr.Invalid) > 0 || len(participantErr.Duplicated) > 0 {
+		return nil, participantErr
+	}
+
+	for i := range listPoke {
+		spec, ok := specified[i]
+		if !ok && listPoke[i].Spec == nil {
+			listPoke[i].CombatPower = formula.CombatPower(listPoke[i])
+			continue
+		}
+		if !ok {
+			spec = *listPoke[i].Spec
+		}
+		p, err := s.Pokemon.ApplyStatSpec(listPoke[i], spec, formula)
+		if err != nil {
+			return nil, &model.ParticipantError{
+				Message: fmt.Sprintf("%s: %s", listPoke[i].Name, err),
+				Invalid: []string{spec.Pokemon},
+			}
+		}
+		listPoke[i] = p
 	}
 
-	result := s.Pokemon.FightPokemon(listPoke)
+	return listPoke, nil
+}
+
+// trainerPokemon resolves the participants a trainer entered, named the same
+// way a fight request names them. A trainer who names none entered the first
+// participant.
+func trainerPokemon(names []string, listPoke []model.Pokemon, trainerID *uint, entered []string) (map[string]bool, error) {
+	if trainerID == nil {
+		if len(entered) > 0 {
+			return nil, &model.ParticipantError{
+				Message: "trainer_pokemon needs a trainer_id",
+			}
+		}
+		return nil, nil
+	}
+	if len(entered) == 0 {
+		return map[string]bool{listPoke[0].Name: true}, nil
+	}
+
+	participants := make(map[string]int, len(names))
+	for i, n := range names {
+		name, _ := helper.NormalizePokemonName(n)
+		participants[name] = i
+	}
+
+	participantErr := &model.ParticipantError{
+		Message: "trainer_pokemon must name participants of the fight, once each",
+	}
+	owned := make(map[string]bool, len(entered))
+	for _, e := range entered {
+		name, _ := helper.NormalizePokemonName(e)
+		i, ok := participants[name]
+		if !ok {
+			participantErr.Invalid = append(participantErr.Invalid, e)
+			continue
+		}
+		if owned[listPoke[i].Name] {
+			participantErr.Duplicated = append(participantErr.Duplicated, e)
+			continue
+		}
+		owned[listPoke[i].Name] = true
+	}
+	if len(participantErr.Invalid) > 0 || len(participantErr.Duplicated) > 0 {
+		return nil, participantErr
+	}
+
+	return owned, nil
+}
 
-	fightHistory, err := s.PokeRepository.InsertFightHistory()
+// getPokemonsData fetches the Pokémon, with their move sets only withMoves,
+// and their combat power from the configured formula applied to their base
+// stats.
+func (s PokeService) getPokemonsData(names []string, withMoves bool) ([]model.Pokemon, error) {
+	formula, err := s.cpFormula("")
 	if err != nil {
 		return nil, err
 	}
 
-	var detailFightData []entity.FightHistoryDetail
-	score := 5
-	for _, r := range result {
-		detailFightData = append(detailFightData, entity.FightHistoryDetail{
-			FightHistoryID: fightHistory.ID,
-			Pokemon:        r.Name,
-			Score:          score,
+	var wg sync.WaitGroup
+	listPoke := make([]model.Pokemon, len(names))
+	errs := make([]error, len(names))
+
+	for i, n := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			listPoke[i], errs[i] = s.GetPokemonData(name, withMoves)
+			if errs[i] == nil {
+				listPoke[i].CombatPower = formula.CombatPower(listPoke[i])
+			}
+		}(i, n)
+	}
+
+	wg.Wait()
+
+	participantErr := &model.ParticipantError{
+		Message: "failed to fetch all Pokemon data",
+	}
+	for i, err := range errs {
+		if errors.Is(err, repository.ErrNotFound) {
+			participantErr.Invalid = append(participantErr.Invalid, names[i])
+		} else if err != nil {
+			participantErr.Unreachable = append(participantErr.Unreachable, names[i])
+		}
+	}
+	if len(participantErr.Invalid) > 0 || len(participantErr.Unreachable) > 0 {
+		return nil, participantErr
+	}
+
+	return listPoke, nil
+}
+
+func battleTurns(result model.BattleResult) []entity.BattleTurn {
+	var turns []entity.BattleTurn
+	for _, t := range result.Log {
+		turns = append(turns, entity.BattleTurn{
+			Turn:          t.Turn,
+			Attacker:      t.Attacker,
+			Defender:      t.Defender,
+			Move:          t.Move,
+			Damage:        t.Damage,
+			Effectiveness: t.Effectiveness,
+			Critical:      t.Critical,
+			Missed:        t.Missed,
+			DefenderHP:    t.DefenderHP,
 		})
-		score--
 	}
-	_, err = s.PokeRepository.InsertFightHistoryDetail(detailFightData)
+	return turns
+}
+
+// resolveMatch settles a one-on-one fight in any mode and returns the
+// fight history to record for it.
+func (s PokeService) resolveMatch(fightHistory entity.FightHistory, scoringRule pokemon.ScoringRule, a model.Pokemon, b model.Pokemon) (entity.FightHistory, error) {
+	entrants := []model.Pokemon{a, b}
+	fightHistory.EngineVersion = pokemon.EngineVersion
+
+	if fightHistory.Mode == pokemon.FightModeBattle {
+		result := s.Pokemon.Battle(a, b, fightHistory.Seed)
+		ranked := entrants
+		if result.Winner != a.Name {
+			ranked = []model.Pokemon{b, a}
+		}
+		fightHistory.BattleTurns = battleTurns(result)
+		return newFightHistory(fightHistory, scoringRule, entrants, ranked, nil), nil
+	}
+
+	// a match needs a winner, so a tie is settled by a coin flip at the latest
+	tieBreakers := s.tieBreakers()
+	coinFlip := false
+	for _, t := range tieBreakers {
+		coinFlip = coinFlip || t == pokemon.TieBreakCoinFlip
+	}
+	if !coinFlip {
+		tieBreakers = append(append([]string(nil), tieBreakers...), pokemon.TieBreakCoinFlip)
+	}
+	fightHistory.TieBreakers = pokemon.FormatTieBreakers(tieBreakers)
+	ranked, err := s.Pokemon.Fight(fightHistory.Mode, append([]model.Pokemon(nil), entrants...), tieBreakers, fightHistory.Seed)
 	if err != nil {
-		return nil, err
+		return entity.FightHistory{}, err
 	}
+	formula, err := s.cpFormula("")
+	if err != nil {
+		return entity.FightHistory{}, err
+	}
+	fightHistory.CPFormula = formula.Name()
+	return newFightHistory(fightHistory, scoringRule, entrants, ranked, nil), nil
+}
 
-	return result, nil
+func (s PokeService) recordFight(fightHistory entity.FightHistory, scoringRule pokemon.ScoringRule, entrants []model.Pokemon, result []model.Pokemon, owned map[string]bool) (entity.FightHistory, error) {
+	return s.PokeRepository.InsertFight(newFightHistory(fightHistory, scoringRule, entrants, result, owned))
 }
 
-func (s PokeService) FightHistories(req model.PokemonReqQuery) ([]entity.FightHistory, error) {
-	fightHistories, err := s.PokeRepository.GetFightHistory(req)
+// newFightHistory scores the result by rank. Only the details of the
+// participants the trainer owns are credited to the fight's trainer.
+func newFightHistory(fightHistory entity.FightHistory, scoringRule pokemon.ScoringRule, entrants []model.Pokemon, result []model.Pokemon, owned map[string]bool) entity.FightHistory {
+	fightHistory.ScoringRule = scoringRule.Name()
+
+	slots := make(map[string]int)
+	for i, e := range entrants {
+		slots[e.Name] = i
+	}
+
+	// battles rank their two participants without setting Rank
+	ranks := make([]int, len(result))
+	shared := make(map[int]int)
+	for i, r := range result {
+		ranks[i] = r.Rank
+		if ranks[i] == 0 {
+			ranks[i] = i + 1
+		}
+		shared[ranks[i]]++
+	}
+
+	for i, r := range result {
+		var trainerID *uint
+		if owned[r.Name] {
+			trainerID = fightHistory.TrainerID
+		}
+		fightHistory.FightHistoryDetail = append(fightHistory.FightHistoryDetail, withStatSpec(entity.FightHistoryDetail{
+			TrainerID: trainerID,
+			Pokemon:   r.Name,
+			Slot:      slots[r.Name],
+			Rank:      ranks[i],
+			Score:     pokemon.SharedScore(scoringRule, ranks[i], shared[ranks[i]], len(result)),
+		}, r))
+	}
+
+	return fightHistory
+}
+
+// withStatSpec records the spec and final stats a participant fought with.
+func withStatSpec(detail entity.FightHistoryDetail, participant model.Pokemon) entity.FightHistoryDetail {
+	if participant.Spec != nil {
+		detail.Level = participant.Spec.Level
+		detail.Nature = participant.Spec.Nature
+		detail.IVs = statValues(participant.Spec.IVs)
+		detail.EVs = statValues(participant.Spec.EVs)
+	}
+	stats := make(map[string]int, len(participant.FinalStats))
+	for _, s := range participant.FinalStats {
+		stats[s.Name] = s.Value
+	}
+	detail.Stats = statValues(stats)
+	return detail
+}
+
+func statValues(values map[string]int) entity.StatValues {
+	return entity.StatValues{
+		HP:             values[pokemon.StatHP],
+		Attack:         values[pokemon.StatAttack],
+		Defense:        values[pokemon.StatDefense],
+		SpecialAttack:  values[pokemon.StatSpecialAttack],
+		SpecialDefense: values[pokemon.StatSpecialDefense],
+		Speed:          values[pokemon.StatSpeed],
+	}
+}
+
+func statMap(values entity.StatValues) map[string]int {
+	stats := map[string]int{
+		pokemon.StatHP:             values.HP,
+		pokemon.StatAttack:         values.Attack,
+		pokemon.StatDefense:        values.Defense,
+		pokemon.StatSpecialAttack:  values.SpecialAttack,
+		pokemon.StatSpecialDefense: values.SpecialDefense,
+		pokemon.StatSpeed:          values.Speed,
+	}
+	for name, value := range stats {
+		if value == 0 {
+			delete(stats, name)
+		}
+	}
+	return stats
+}
+
+// seasonScoringRule returns the season a new fight belongs to, if one is
+// active, and the scoring rule for it: the requested one, else the season's,
+// else the configured default.
+func (s PokeService) seasonScoringRule(name string) (*uint, pokemon.ScoringRule, error) {
+	var seasonID *uint
+	season, err := s.PokeRepository.GetActiveSeason(time.Now())
+	if err == nil {
+		seasonID = &season.ID
+		if name == "" {
+			name = season.ScoringRule
+		}
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	scoringRule, err := s.scoringRule(name)
 	if err != nil {
-		return []entity.FightHistory{}, err
+		return nil, nil, err
 	}
+	return seasonID, scoringRule, nil
+}
 
-	return fightHistories, nil
+func (s PokeService) scoringRule(name string) (pokemon.ScoringRule, error) {
+	if name == "" {
+		name = s.FightConfig.ScoringRule
+	}
+	scoringRule, err := pokemon.NewScoringRule(name, s.FightConfig.ScoringTable)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidScoringRule, err)
+	}
+	return scoringRule, nil
 }
 
-func (s PokeService) GetLeaderboard() ([]model.Leaderboard, error) {
-	leaderboardData, err := s.PokeRepository.GetSumScore()
+func (s PokeService) tieBreakers() []string {
+	if s.FightConfig.TieBreakers == nil {
+		return pokemon.DefaultTieBreakers
+	}
+	return s.FightConfig.TieBreakers
+}
+
+// cpFormula resolves the requested formula, else the configured default.
+func (s PokeService) cpFormula(name string) (pokemon.CPFormula, error) {
+	if name == "" {
+		name = s.FightConfig.CPFormula
+	}
+	formula, err := pokemon.NewCPFormula(name, s.FightConfig.CPWeights)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCPFormula, err)
+	}
+	return formula, nil
+}
+
+func newSeed(seed *int64) int64 {
+	if seed != nil {
+		return *seed
+	}
+	return rand.Int63()
+}
+
+func (s PokeService) FightHistories(req model.PokemonReqQuery) ([]entity.FightHistory, model.CursorPage, error) {
+	limit := req.Limit
+	req.Limit++
+	fightHistories, total, err := s.PokeRepository.GetFightHistory(req)
 	if err != nil {
-		return []model.Leaderboard{}, err
+		return []entity.FightHistory{}, model.CursorPage{}, err
+	}
+
+	page := model.CursorPage{Total: total}
+	if len(fightHistories) > limit {
+		fightHistories = fightHistories[:limit]
+		page.NextCursor = helper.EncodeCursor(fightHistories[limit-1].ID)
+	}
+
+	return fightHistories, page, nil
+}
+
+func (s PokeService) GetLeaderboard(req model.LeaderboardReqQuery) ([]model.Leaderboard, int, error) {
+	leaderboardData, err := s.PokeRepository.GetSumScore(req)
+	if err != nil {
+		return []model.Leaderboard{}, 0, err
+	}
+
+	leaderboardData = pokemon.RankLeaderboard(leaderboardData)
+	page, total := pageLeaderboard(leaderboardData, req.Offset, req.Limit)
+	return page, total, nil
+}
+
+func pageLeaderboard(leaderboard []model.Leaderboard, offset int, limit int) ([]model.Leaderboard, int) {
+	total := len(leaderboard)
+	if offset >= total {
+		return []model.Leaderboard{}, total
+	}
+	leaderboard = leaderboard[offset:]
+	if limit > 0 && limit < len(leaderboard) {
+		leaderboard = leaderboard[:limit]
 	}
+	return leaderboard, total
+}
 
-	return leaderboardData, nil
+func (s PokeService) RescoreFights(req model.RescoreReqBody) (int, error) {
+	var scoringRule pokemon.ScoringRule
+	if req.Scoring != "" {
+		rule, err := s.scoringRule(req.Scoring)
+		if err != nil {
+			return 0, err
+		}
+		scoringRule = rule
+	}
+
+	return s.PokeRepository.RescoreFightHistories(func(fightHistory entity.FightHistory) (pokemon.ScoringRule, error) {
+		if scoringRule != nil {
+			return scoringRule, nil
+		}
+		return pokemon.NewScoringRule(fightHistory.ScoringRule, nil)
+	})
 }
 
+// CancelPokemon cancels a participant named as in the fight request; a name
+// that cannot be a Pokemon is not in the fight either.
 func (s PokeService) CancelPokemon(req model.PokemonCancelReqBody) (entity.FightHistoryDetail, error) {
+	name, ok := helper.NormalizePokemonName(req.Pokemon)
+	if !ok {
+		return entity.FightHistoryDetail{}, fmt.Errorf("%s in fight history %d: %w", req.Pokemon, req.FightHistoryID, repository.ErrNotFound)
+	}
+	req.Pokemon = name
+
 	fightHistoryDetail, err := s.PokeRepository.CancelScorePokemon(req)
 	if err != nil {
 		return entity.FightHistoryDetail{}, err
@@ -120,3 +1079,21 @@ func (s PokeService) CancelPokemon(req model.PokemonCancelReqBody) (entity.Fight
 
 	return fightHistoryDetail, nil
 }
+
+func (s PokeService) RevertCancellation(auditID uint, req model.CancelRevertReqBody) (entity.CancellationAudit, error) {
+	audit, err := s.PokeRepository.RevertCancellation(auditID, req)
+	if err != nil {
+		return entity.CancellationAudit{}, err
+	}
+
+	return audit, nil
+}
+
+func (s PokeService) FightAudit(fightHistoryID uint) ([]entity.CancellationAudit, error) {
+	audits, err := s.PokeRepository.GetCancellationAudits(fightHistoryID)
+	if err != nil {
+		return []entity.CancellationAudit{}, err
+	}
+
+	return audits, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"pokeapi/model"
+	"pokeapi/repository"
+	"sync"
+	"testing"
+)
+
+// moveDataSource serves moves named "hit-N", which deal damage, "growl-N",
+// which do not, and "boom-N", which fail, and counts the fetches.
+type moveDataSource struct {
+	repository.PokeDataSource
+	mu      sync.Mutex
+	fetched []string
+}
+
+func (d *moveDataSource) GetMove(name string) (model.PokeMoveDataSourceRes, error) {
+	d.mu.Lock()
+	d.fetched = append(d.fetched, name)
+	d.mu.Unlock()
+
+	var move model.PokeMoveDataSourceRes
+	move.Name = name
+	move.DamageClass.Name = "status"
+	switch name[:4] {
+	case "hit-":
+		power := 40
+		move.Power = &power
+		move.DamageClass.Name = "physical"
+	case "boom":
+		return move, errors.New("pokeapi unreachable")
+	case "miss":
+		return move, fmt.Errorf("move %s: %w", name, repository.ErrNotFound)
+	}
+	return move, nil
+}
+
+func TestGetMoveSetStopsFetchingOnceComplete(t *testing.T) {
+	var names []string
+	for i := 0; i < 30; i++ {
+		names = append(names, fmt.Sprintf("hit-%d", i))
+	}
+	names[1] = "growl-1"
+	names[2] = "miss-2"
+
+	dataSource := &moveDataSource{}
+	moves, err := PokeService{PokeDataSource: dataSource}.getMoveSet(names)
+	assert.NoError(t, err)
+	assert.Len(t, moves, 4)
+	assert.Equal(t, "hit-0", moves[0].Name)
+	assert.Equal(t, "hit-5", moves[3].Name)
+	assert.Len(t, dataSource.fetched, 8)
+}
+
+func TestGetMoveSetIgnoresFailuresAfterComplete(t *testing.T) {
+	dataSource := &moveDataSource{}
+	moves, err := PokeService{PokeDataSource: dataSource}.getMoveSet([]string{"hit-0", "hit-1", "hit-2", "hit-3", "boom-4"})
+	assert.NoError(t, err)
+	assert.Len(t, moves, 4)
+	assert.Len(t, dataSource.fetched, 4)
+
+	dataSource = &moveDataSource{}
+	_, err = PokeService{PokeDataSource: dataSource}.getMoveSet([]string{"hit-0", "boom-1", "hit-2", "hit-3", "hit-4"})
+	assert.Error(t, err)
+}
+
+func TestTeamDuplicates(t *testing.T) {
+	names := []string{"pikachu", "eevee", "pikachu", "onix", "onix", "eevee"}
+
+	assert.Empty(t, teamDuplicates(names, 2))
+	assert.Equal(t, []string{"onix"}, teamDuplicates([]string{"pikachu", "eevee", "onix", "onix"}, 2))
+	assert.Equal(t, []string{"pikachu", "onix", "eevee"}, teamDuplicates(names, 6))
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pokeapi/entity"
+	"pokeapi/helper"
+	"pokeapi/pokemon"
+	"pokeapi/repository"
+)
+
+var (
+	ErrInvalidRatingSystem = errors.New("invalid rating system")
+	ErrInvalidPokemonName  = errors.New("invalid pokemon name")
+)
+
+type RatingService struct {
+	RatingRepository repository.RatingRepository
+}
+
+func NewRatingService(ratingRepository *repository.RatingRepository) RatingService {
+	return RatingService{
+		RatingRepository: *ratingRepository,
+	}
+}
+
+func (s RatingService) GetRatings(system string, offset int, limit int) ([]entity.PokemonRating, int64, error) {
+	if system == "" {
+		system = pokemon.RatingSystemElo
+	}
+	if system != pokemon.RatingSystemElo && system != pokemon.RatingSystemGlicko2 {
+		return []entity.PokemonRating{}, 0, fmt.Errorf("%w: system must be %s or %s", ErrInvalidRatingSystem, pokemon.RatingSystemElo, pokemon.RatingSystemGlicko2)
+	}
+
+	return s.RatingRepository.GetRatings(system, offset, limit)
+}
+
+func (s RatingService) GetRatingHistory(name string) ([]entity.RatingHistory, error) {
+	pokemonName, ok := helper.NormalizePokemonName(name)
+	if !ok {
+		return []entity.RatingHistory{}, fmt.Errorf("%w %q", ErrInvalidPokemonName, name)
+	}
+
+	return s.RatingRepository.GetRatingHistory(pokemonName)
+}
+
+func (s RatingService) Recompute() (int, error) {
+	return s.RatingRepository.RecomputeRatings()
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pokeapi/entity"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"pokeapi/repository"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSeason = errors.New("invalid season")
+
+type SeasonService struct {
+	SeasonRepository repository.SeasonRepository
+	FightConfig      model.FightConfig
+}
+
+func NewSeasonService(seasonRepository *repository.SeasonRepository, fightConfig model.FightConfig) SeasonService {
+	return SeasonService{
+		SeasonRepository: *seasonRepository,
+		FightConfig:      fightConfig,
+	}
+}
+
+func (s SeasonService) Open(req model.SeasonCreateReqBody) (entity.Season, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return entity.Season{}, fmt.Errorf("%w: season name must be between 1 and 100 characters", ErrInvalidSeason)
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(startsAt) {
+		return entity.Season{}, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidSeason)
+	}
+
+	scoringRule := ""
+	if req.Scoring != "" {
+		rule, err := pokemon.NewScoringRule(req.Scoring, s.FightConfig.ScoringTable)
+		if err != nil {
+			return entity.Season{}, fmt.Errorf("%w: %s", ErrInvalidSeason, err)
+		}
+		scoringRule = rule.Name()
+	}
+
+	return s.SeasonRepository.InsertSeason(entity.Season{
+		Name:        name,
+		ScoringRule: scoringRule,
+		StartsAt:    startsAt,
+		EndsAt:      req.EndsAt,
+	}, time.Now())
+}
+
+func (s SeasonService) Close(id uint) (entity.Season, error) {
+	return s.SeasonRepository.CloseSeason(id, time.Now())
+}
+
+func (s SeasonService) GetSeasons() ([]entity.Season, error) {
+	return s.SeasonRepository.GetSeasons()
+}
+
+// GetLeaderboard returns the frozen standings of a closed season and the live
+// ranking of an open one.
+func (s SeasonService) GetLeaderboard(id uint, offset int, limit int) ([]model.Leaderboard, int, error) {
+	season, err := s.SeasonRepository.GetSeasonByID(id)
+	if err != nil {
+		return []model.Leaderboard{}, 0, err
+	}
+
+	var leaderboard []model.Leaderboard
+	if season.ClosedAt != nil {
+		leaderboard, err = s.SeasonRepository.GetSeasonStandings(season.ID)
+	} else {
+		leaderboard, err = s.SeasonRepository.GetSeasonSumScore(season.ID)
+		leaderboard = pokemon.RankLeaderboard(leaderboard)
+	}
+	if err != nil {
+		return []model.Leaderboard{}, 0, err
+	}
+
+	page, total := pageLeaderboard(leaderboard, offset, limit)
+	return page, total, nil
+}
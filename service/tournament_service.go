@@ -0,0 +1,342 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pokeapi/entity"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"pokeapi/repository"
+	"sort"
+	"strings"
+)
+
+const maxTournamentEntrants = 64
+
+var ErrInvalidTournament = errors.New("invalid tournament")
+
+type TournamentService struct {
+	TournamentRepository repository.TournamentRepository
+	RatingRepository     repository.RatingRepository
+	PokeService          PokeService
+}
+
+func NewTournamentService(tournamentRepository *repository.TournamentRepository, ratingRepository *repository.RatingRepository, pokeService *PokeService) TournamentService {
+	return TournamentService{
+		TournamentRepository: *tournamentRepository,
+		RatingRepository:     *ratingRepository,
+		PokeService:          *pokeService,
+	}
+}
+
+func (s TournamentService) Create(req model.TournamentCreateReqBody) (entity.Tournament, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return entity.Tournament{}, fmt.Errorf("%w: tournament name must be between 1 and 100 characters", ErrInvalidTournament)
+	}
+	if !pokemon.IsValidTournamentFormat(req.Format) {
+		return entity.Tournament{}, fmt.Errorf("%w: unknown tournament format %q", ErrInvalidTournament, req.Format)
+	}
+	if req.Rounds < 0 || (req.Rounds > 0 && req.Format != pokemon.TournamentSwiss) {
+		return entity.Tournament{}, fmt.Errorf("%w: rounds can only be set for %s tournaments", ErrInvalidTournament, pokemon.TournamentSwiss)
+	}
+
+	seeding := req.Seeding
+	if seeding == "" {
+		seeding = model.TournamentSeedingCombatPower
+	}
+	if seeding != model.TournamentSeedingCombatPower && seeding != model.TournamentSeedingRating {
+		return entity.Tournament{}, fmt.Errorf("%w: unknown seeding %q", ErrInvalidTournament, seeding)
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = pokemon.FightModeCombatPower
+	}
+	if mode != pokemon.FightModeCombatPower && mode != pokemon.FightModeType && mode != pokemon.FightModeBattle {
+		return entity.Tournament{}, fmt.Errorf("%w: %q", ErrInvalidFightMode, mode)
+	}
+
+	scoringRule := ""
+	if req.Scoring != "" {
+		rule, err := s.PokeService.scoringRule(req.Scoring)
+		if err != nil {
+			return entity.Tournament{}, err
+		}
+		scoringRule = rule.Name()
+	}
+
+	tournament := entity.Tournament{
+		Name:        name,
+		Format:      req.Format,
+		Rounds:      req.Rounds,
+		Seeding:     seeding,
+		Mode:        mode,
+		Seed:        newSeed(req.Seed),
+		ScoringRule: scoringRule,
+		TrainerID:   req.TrainerID,
+		Status:      model.TournamentStatusRegistration,
+	}
+	if len(req.Pokemon) > 0 {
+		listPoke, err := s.PokeService.getParticipants(req.Pokemon, 1, maxTournamentEntrants, false)
+		if err != nil {
+			return entity.Tournament{}, err
+		}
+		for _, p := range listPoke {
+			tournament.Entrants = append(tournament.Entrants, entity.TournamentEntrant{Pokemon: p.Name})
+		}
+	}
+
+	return s.TournamentRepository.InsertTournament(tournament)
+}
+
+func (s TournamentService) AddEntrants(id uint, req model.TournamentEntrantReqBody) (entity.Tournament, error) {
+	listPoke, err := s.PokeService.getParticipants(req.Pokemon, 1, maxTournamentEntrants, false)
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+
+	return s.TournamentRepository.UpdateTournament(id, func(tournament entity.Tournament) (entity.Tournament, error) {
+		if tournament.Status != model.TournamentStatusRegistration {
+			return entity.Tournament{}, fmt.Errorf("tournament %d already started: %w", id, repository.ErrConflict)
+		}
+
+		registered := make(map[string]bool)
+		for _, e := range tournament.Entrants {
+			registered[e.Pokemon] = true
+		}
+		participantErr := &model.ParticipantError{
+			Message: "invalid participants",
+		}
+		for _, p := range listPoke {
+			if registered[p.Name] {
+				participantErr.Duplicated = append(participantErr.Duplicated, p.Name)
+			}
+			tournament.Entrants = append(tournament.Entrants, entity.TournamentEntrant{Pokemon: p.Name})
+		}
+		if len(participantErr.Duplicated) > 0 {
+			return entity.Tournament{}, participantErr
+		}
+		if len(tournament.Entrants) > maxTournamentEntrants {
+			return entity.Tournament{}, &model.ParticipantError{
+				Message: fmt.Sprintf("a tournament takes at most %d Pokemon", maxTournamentEntrants),
+			}
+		}
+
+		return tournament, nil
+	})
+}
+
+// Start seeds the entrants by combat power or Elo rating, best first, and
+// draws the first round.
+func (s TournamentService) Start(id uint) (entity.Tournament, error) {
+	tournament, err := s.TournamentRepository.GetTournamentByID(id)
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+	seedingValues, err := s.seedingValues(tournament)
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+
+	return s.TournamentRepository.UpdateTournament(id, func(tournament entity.Tournament) (entity.Tournament, error) {
+		if tournament.Status != model.TournamentStatusRegistration {
+			return entity.Tournament{}, fmt.Errorf("tournament %d already started: %w", id, repository.ErrConflict)
+		}
+		if len(tournament.Entrants) < 2 {
+			return entity.Tournament{}, &model.ParticipantError{
+				Message: fmt.Sprintf("a tournament needs at least 2 Pokemon, got %d", len(tournament.Entrants)),
+			}
+		}
+		for _, e := range tournament.Entrants {
+			if _, ok := seedingValues[e.Pokemon]; !ok {
+				return entity.Tournament{}, fmt.Errorf("tournament %d entrants changed: %w", id, repository.ErrConflict)
+			}
+		}
+
+		entrants := tournament.Entrants
+		sort.SliceStable(entrants, func(i, j int) bool {
+			if seedingValues[entrants[i].Pokemon] != seedingValues[entrants[j].Pokemon] {
+				return seedingValues[entrants[i].Pokemon] > seedingValues[entrants[j].Pokemon]
+			}
+			return entrants[i].Pokemon < entrants[j].Pokemon
+		})
+		for i := range entrants {
+			entrants[i].Seed = i + 1
+			entrants[i].SeedingValue = seedingValues[entrants[i].Pokemon]
+		}
+
+		if tournament.Format == pokemon.TournamentSwiss {
+			maxRounds := len(entrants) - 1 + len(entrants)%2
+			if tournament.Rounds == 0 {
+				tournament.Rounds = pokemon.SwissRounds(len(entrants))
+			}
+			if tournament.Rounds > maxRounds {
+				tournament.Rounds = maxRounds
+			}
+		}
+		tournament.Status = model.TournamentStatusRunning
+		tournament.Matches = append(tournament.Matches, tournamentMatches(tournamentState(tournament).NextRound())...)
+		return tournament, nil
+	})
+}
+
+// Play fights every open match of the current round, draws the next round
+// and, with all set, keeps going until the tournament is finished.
+func (s TournamentService) Play(id uint, req model.TournamentPlayReqBody) (entity.Tournament, error) {
+	tournament, err := s.TournamentRepository.GetTournamentByID(id)
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+	if tournament.Status != model.TournamentStatusRunning {
+		return entity.Tournament{}, fmt.Errorf("tournament %d is not running: %w", id, repository.ErrConflict)
+	}
+
+	names := make([]string, len(tournament.Entrants))
+	for i, e := range tournament.Entrants {
+		names[i] = e.Pokemon
+	}
+	listPoke, err := s.PokeService.getPokemonsData(names, tournament.Mode == pokemon.FightModeBattle)
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+	entrants := make(map[string]model.Pokemon, len(listPoke))
+	for i, p := range listPoke {
+		entrants[names[i]] = p
+	}
+
+	seasonID, scoringRule, err := s.PokeService.seasonScoringRule(tournament.ScoringRule)
+	if err != nil {
+		return entity.Tournament{}, err
+	}
+
+	return s.TournamentRepository.UpdateTournament(id, func(tournament entity.Tournament) (entity.Tournament, error) {
+		if tournament.Status != model.TournamentStatusRunning {
+			return entity.Tournament{}, fmt.Errorf("tournament %d is not running: %w", id, repository.ErrConflict)
+		}
+
+		for {
+			for i, m := range tournament.Matches {
+				if m.Winner != "" {
+					continue
+				}
+				a, okA := entrants[m.PokemonA]
+				b, okB := entrants[m.PokemonB]
+				if !okA || !okB {
+					return entity.Tournament{}, fmt.Errorf("tournament %d entrants changed: %w", id, repository.ErrConflict)
+				}
+
+				fightHistory, err := s.PokeService.resolveMatch(matchFightHistory(tournament, m, seasonID), scoringRule, a, b)
+				if err != nil {
+					return entity.Tournament{}, err
+				}
+				tournament.Matches[i].Winner = fightHistory.FightHistoryDetail[0].Pokemon
+				tournament.Matches[i].Fight = &fightHistory
+			}
+
+			state := tournamentState(tournament)
+			next := state.NextRound()
+			if next == nil {
+				tournament.Status = model.TournamentStatusFinished
+				tournament.Champion = state.Champion()
+				return tournament, nil
+			}
+			tournament.Matches = append(tournament.Matches, tournamentMatches(next)...)
+			if !req.All {
+				return tournament, nil
+			}
+		}
+	})
+}
+
+func (s TournamentService) GetTournaments() ([]entity.Tournament, error) {
+	return s.TournamentRepository.GetTournaments()
+}
+
+func (s TournamentService) GetTournament(id uint) (entity.Tournament, []pokemon.TournamentStanding, error) {
+	tournament, err := s.TournamentRepository.GetTournamentByID(id)
+	if err != nil {
+		return entity.Tournament{}, nil, err
+	}
+
+	var standings []pokemon.TournamentStanding
+	if tournament.Status != model.TournamentStatusRegistration {
+		standings = tournamentState(tournament).Standings()
+	}
+	return tournament, standings, nil
+}
+
+func (s TournamentService) seedingValues(tournament entity.Tournament) (map[string]float64, error) {
+	names := make([]string, len(tournament.Entrants))
+	for i, e := range tournament.Entrants {
+		names[i] = e.Pokemon
+	}
+
+	values := make(map[string]float64, len(names))
+	if tournament.Seeding == model.TournamentSeedingRating {
+		ratings, err := s.RatingRepository.GetRatingsByPokemon(names)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			values[name] = pokemon.DefaultEloRating
+			if rating, ok := ratings[name]; ok {
+				values[name] = rating.Elo
+			}
+		}
+		return values, nil
+	}
+
+	listPoke, err := s.PokeService.getPokemonsData(names, false)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range listPoke {
+		values[names[i]] = p.CombatPower
+	}
+	return values, nil
+}
+
+// matchFightHistory is the fight a match is recorded as. The tournament's
+// trainer organised it rather than entered either participant, so the fight
+// is not credited to a trainer.
+func matchFightHistory(tournament entity.Tournament, match entity.TournamentMatch, seasonID *uint) entity.FightHistory {
+	return entity.FightHistory{
+		Mode:     tournament.Mode,
+		Seed:     tournament.Seed + int64(match.Round)*1000 + int64(match.Position),
+		SeasonID: seasonID,
+	}
+}
+
+func tournamentState(tournament entity.Tournament) pokemon.Tournament {
+	state := pokemon.Tournament{Format: tournament.Format, Rounds: tournament.Rounds}
+	for _, e := range tournament.Entrants {
+		state.Entrants = append(state.Entrants, pokemon.TournamentEntrant{Pokemon: e.Pokemon, Seed: e.Seed})
+	}
+	for _, m := range tournament.Matches {
+		state.Matches = append(state.Matches, pokemon.TournamentMatch{
+			Round:    m.Round,
+			Position: m.Position,
+			Bracket:  m.Bracket,
+			PokemonA: m.PokemonA,
+			PokemonB: m.PokemonB,
+			Winner:   m.Winner,
+		})
+	}
+	return state
+}
+
+func tournamentMatches(matches []pokemon.TournamentMatch) []entity.TournamentMatch {
+	var tournamentMatches []entity.TournamentMatch
+	for _, m := range matches {
+		tournamentMatches = append(tournamentMatches, entity.TournamentMatch{
+			Round:    m.Round,
+			Position: m.Position,
+			Bracket:  m.Bracket,
+			PokemonA: m.PokemonA,
+			PokemonB: m.PokemonB,
+			Winner:   m.Winner,
+		})
+	}
+	return tournamentMatches
+}
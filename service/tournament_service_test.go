@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pokeapi/entity"
+	"pokeapi/model"
+	"pokeapi/pokemon"
+	"testing"
+)
+
+func TestMatchFightHistoryIsNotCreditedToTrainer(t *testing.T) {
+	trainerID := uint(7)
+	seasonID := uint(3)
+	tournament := entity.Tournament{Mode: pokemon.FightModeCombatPower, Seed: 42, TrainerID: &trainerID}
+	match := entity.TournamentMatch{Round: 2, Position: 1, PokemonA: "pikachu", PokemonB: "eevee"}
+
+	fightHistory := matchFightHistory(tournament, match, &seasonID)
+	assert.Nil(t, fightHistory.TrainerID)
+	assert.Equal(t, &seasonID, fightHistory.SeasonID)
+	assert.Equal(t, int64(2043), fightHistory.Seed)
+
+	rule, err := pokemon.NewScoringRule("", nil)
+	assert.NoError(t, err)
+	fightHistory, err = PokeService{}.resolveMatch(fightHistory, rule,
+		model.Pokemon{Name: "pikachu", CombatPower: 60},
+		model.Pokemon{Name: "eevee", CombatPower: 50},
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, fightHistory.TrainerID)
+	assert.Len(t, fightHistory.FightHistoryDetail, 2)
+	for _, d := range fightHistory.FightHistoryDetail {
+		assert.Nil(t, d.TrainerID)
+	}
+	assert.Equal(t, "pikachu", fightHistory.FightHistoryDetail[0].Pokemon)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pokeapi/entity"
+	"pokeapi/model"
+	"pokeapi/repository"
+	"strings"
+)
+
+var ErrInvalidTrainer = errors.New("invalid trainer")
+
+type TrainerService struct {
+	TrainerRepository repository.TrainerRepository
+}
+
+func NewTrainerService(trainerRepository *repository.TrainerRepository) TrainerService {
+	return TrainerService{
+		TrainerRepository: *trainerRepository,
+	}
+}
+
+func (s TrainerService) Register(req model.TrainerCreateReqBody) (entity.Trainer, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return entity.Trainer{}, fmt.Errorf("%w: trainer name must be between 1 and 100 characters", ErrInvalidTrainer)
+	}
+
+	trainer, err := s.TrainerRepository.InsertTrainer(entity.Trainer{
+		Name: name,
+	})
+	if err != nil {
+		return entity.Trainer{}, err
+	}
+
+	return trainer, nil
+}
+
+func (s TrainerService) GetTrainer(id uint) (entity.Trainer, error) {
+	trainer, err := s.TrainerRepository.GetTrainerByID(id)
+	if err != nil {
+		return entity.Trainer{}, err
+	}
+
+	return trainer, nil
+}
+
+func (s TrainerService) TrainerFights(id uint) ([]entity.FightHistory, error) {
+	_, err := s.TrainerRepository.GetTrainerByID(id)
+	if err != nil {
+		return []entity.FightHistory{}, err
+	}
+
+	fightHistories, err := s.TrainerRepository.GetTrainerFights(id)
+	if err != nil {
+		return []entity.FightHistory{}, err
+	}
+
+	return fightHistories, nil
+}
+
+func (s TrainerService) GetLeaderboard() ([]model.TrainerLeaderboard, error) {
+	leaderboardData, err := s.TrainerRepository.GetTrainerSumScore()
+	if err != nil {
+		return []model.TrainerLeaderboard{}, err
+	}
+
+	return leaderboardData, nil
+}
+
+func (s TrainerService) GetTeamLeaderboard() ([]model.TeamLeaderboard, error) {
+	leaderboardData, err := s.TrainerRepository.GetTeamSumScore()
+	if err != nil {
+		return []model.TeamLeaderboard{}, err
+	}
+
+	return leaderboardData, nil
+}